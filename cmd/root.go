@@ -3,32 +3,99 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
 	"github.com/Johannes-Berggren/GitGoblin/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+// repoPath is the directory given with --path
+var repoPath string
+
+// Set at build time, e.g. -ldflags "-X github.com/Johannes-Berggren/GitGoblin/cmd.version=1.2.0"
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
 var rootCmd = &cobra.Command{
-	Use:   "goblin",
+	Use:   "goblin [file | repo]",
 	Short: "A terminal-based Git client",
-	Long:  `GitGoblin - A lightweight, terminal-based Git client inspired by GitKraken`,
+	Long: `GitGoblin - A lightweight, terminal-based Git client inspired by GitKraken
+
+Pass a changed file to open straight into its diff in the staging view, or
+a directory to open the repository it belongs to, like --path.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// Check if we're in a git repo
-		if !isGitRepo() {
-			fmt.Println("Error: Not a git repository")
+		// A directory argument names the repository rather than a file
+		if len(args) == 1 && isDir(args[0]) {
+			if cmd.Flags().Changed("path") {
+				fmt.Println("Error: pass the repository either as an argument or with --path, not both")
+				os.Exit(1)
+			}
+			repoPath = args[0]
+			args = nil
+		}
+
+		repo, err := openRepo()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		theme, err := ui.LoadTheme(cfg)
+		if err != nil {
+			fmt.Printf("Error loading theme: %v\n", err)
 			os.Exit(1)
 		}
 
+		model := ui.NewModel(repo, cfg, theme).WithVersion(versionString())
+		if len(args) == 1 {
+			path, err := changedFilePath(repo, args[0])
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			model = model.OpenFile(path)
+		} else if cfg.RestoreSession {
+			// Without a saved session this starts on the dashboard as usual
+			session, _ := config.LoadSession(repo.Root)
+			model = model.RestoreSession(session)
+		}
+
 		// Initialize and run the TUI
-		p := tea.NewProgram(ui.NewModel(), tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		final, err := p.Run()
+		if err != nil {
 			fmt.Printf("Error running app: %v\n", err)
 			os.Exit(1)
 		}
+
+		if cfg.RestoreSession {
+			if err := config.SaveSession(repo.Root, final.(ui.Model).Session()); err != nil {
+				fmt.Printf("Error saving session: %v\n", err)
+			}
+		}
 	},
 }
 
+func init() {
+	rootCmd.Version = versionString()
+	rootCmd.PersistentFlags().StringVar(&repoPath, "path", ".", "repository to open, any directory inside its working tree")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -36,7 +103,80 @@ func Execute() {
 	}
 }
 
-func isGitRepo() bool {
-	_, err := os.Stat(".git")
-	return err == nil
+// changedFilePath turns a file argument, relative to the working directory,
+// into the repository-relative path git status reports, checking that the
+// file has changes to show
+func changedFilePath(repo *git.Repo, arg string) (string, error) {
+	path := filepath.Clean(arg)
+	if !filepath.IsAbs(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(cwd, path)
+	}
+	// git reports the root with symlinks resolved, e.g. /private/tmp on macOS
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		path = filepath.Join(dir, filepath.Base(path))
+	}
+	path, err := filepath.Rel(repo.Root, path)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.ToSlash(path)
+	if path == ".." || strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("%s is outside the repository", arg)
+	}
+
+	files, err := git.GetWorkingTreeStatus()
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if file.Path == path || file.OldPath == path {
+			return file.Path, nil
+		}
+		// Untracked directories are listed as a whole, "dir/"
+		if strings.HasSuffix(file.Path, "/") && strings.HasPrefix(path, file.Path) {
+			return file.Path, nil
+		}
+	}
+	return "", fmt.Errorf("%s has no changes", arg)
+}
+
+// openRepo resolves the repository given with --path, first making sure a
+// recent enough git is installed so that fails up front rather than mid-TUI
+func openRepo() (*git.Repo, error) {
+	if _, err := git.CheckVersion(); err != nil {
+		return nil, err
+	}
+	return git.Open(repoPath)
+}
+
+// versionString describes this build, falling back to the module version
+// for binaries built with go install
+func versionString() string {
+	v := version
+	if v == "dev" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+	}
+	if commit != "" {
+		short := commit
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		v += " (" + short
+		if date != "" {
+			v += ", " + date
+		}
+		v += ")"
+	}
+	return v
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
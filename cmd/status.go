@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var statusJSON bool
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print a one-line repository summary",
+	Long: `Print the branch, ahead/behind counts against its upstream, the number of
+changed files and the lines added/deleted on a single line, then exit.
+Handy for shell prompts and scripts, e.g. "main ↑2 ↓0 3 files +20/-8".
+
+With --json the full state is printed as JSON instead, including every
+changed file with its line stats and the last commit.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, err := openRepo()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		git.Use(repo)
+
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		status, err := collectStatus(cfg)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if statusJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(status); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		fmt.Println(status.line())
+	},
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "print the repository state as JSON")
+	rootCmd.AddCommand(statusCmd)
+}
+
+// repoStatus is the repository state printed by goblin status
+type repoStatus struct {
+	Repo         string         `json:"repo"`
+	Branch       string         `json:"branch"` // empty when HEAD is detached
+	Detached     bool           `json:"detached"`
+	Head         string         `json:"head,omitempty"`     // short hash of a detached HEAD
+	Upstream     string         `json:"upstream,omitempty"` // e.g. "origin/main"
+	Ahead        int            `json:"ahead"`
+	Behind       int            `json:"behind"`
+	LastCommit   *models.Commit `json:"last_commit"` // nil in a repository without commits
+	Files        []fileStatus   `json:"files"`
+	LinesAdded   int            `json:"lines_added"`
+	LinesDeleted int            `json:"lines_deleted"`
+}
+
+// fileStatus is a changed file with its line stats
+type fileStatus struct {
+	models.FileChange
+	Added   int `json:"added"`
+	Deleted int `json:"deleted"`
+}
+
+// collectStatus gathers the repository state. Files matched by
+// exclude_paths are left out, as on the dashboard.
+func collectStatus(cfg *config.Config) (repoStatus, error) {
+	status := repoStatus{Files: []fileStatus{}}
+
+	branch, detached, err := git.GetHead()
+	if err != nil {
+		return status, err
+	}
+	status.Repo, _ = git.GetRepoName()
+
+	if detached {
+		status.Detached = true
+		status.Head = branch
+	} else {
+		status.Branch = branch
+		if remote, remoteBranch, err := git.GetUpstream(branch); err == nil && remote != "" {
+			// The upstream branch may be configured but gone from the remote
+			if ahead, behind, err := git.GetAheadBehind("@{upstream}"); err == nil {
+				status.Upstream = remote + "/" + remoteBranch
+				status.Ahead, status.Behind = ahead, behind
+			}
+		}
+	}
+
+	if commit, err := git.GetLastCommit(); err == nil {
+		status.LastCommit = &commit
+	}
+
+	files, err := git.GetWorkingTreeStatus()
+	if err != nil {
+		return status, err
+	}
+	stats, err := git.GetLineStats()
+	if err != nil {
+		return status, err
+	}
+
+	for _, file := range files {
+		if cfg.IsExcluded(file.Path) {
+			continue
+		}
+		added, deleted := stats[file.Path][0], stats[file.Path][1]
+		status.Files = append(status.Files, fileStatus{FileChange: file, Added: added, Deleted: deleted})
+		status.LinesAdded += added
+		status.LinesDeleted += deleted
+	}
+
+	return status, nil
+}
+
+// line formats the status as a single line for shell prompts
+func (s repoStatus) line() string {
+	var parts []string
+	if s.Detached {
+		label := "detached"
+		if s.Head != "" {
+			label += "@" + s.Head
+		}
+		parts = append(parts, label)
+	} else {
+		parts = append(parts, s.Branch)
+		if s.Upstream != "" {
+			parts = append(parts, fmt.Sprintf("↑%d ↓%d", s.Ahead, s.Behind))
+		}
+	}
+
+	noun := "files"
+	if len(s.Files) == 1 {
+		noun = "file"
+	}
+	parts = append(parts, fmt.Sprintf("%d %s", len(s.Files), noun), fmt.Sprintf("+%d/-%d", s.LinesAdded, s.LinesDeleted))
+
+	return strings.Join(parts, " ")
+}
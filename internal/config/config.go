@@ -0,0 +1,202 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds user settings read from ~/.config/gitgoblin/config.toml
+type Config struct {
+	// ExcludePaths lists glob patterns for files hidden from the file lists,
+	// e.g. ["*.pb.go", "vendor/**"]
+	ExcludePaths []string `toml:"exclude_paths"`
+
+	// FullHashes shows full 40-character hashes in the graph and branch
+	// views. The # key switches them for the current run without changing
+	// this setting.
+	FullHashes bool `toml:"full_hashes"`
+
+	// CommitTrailers lists the trailer keys offered by the commit flow's
+	// trailer picker (ctrl+t)
+	CommitTrailers []string `toml:"commit_trailers"`
+
+	// CommitTypes lists the Conventional Commits types offered by the
+	// commit flow's type picker (ctrl+y)
+	CommitTypes []string `toml:"commit_types"`
+
+	// ProtectedBranches lists branch names or glob patterns, e.g.
+	// "release/*", that the commit flow asks for confirmation before
+	// committing to. Unset means the detected default branch.
+	ProtectedBranches []string `toml:"protected_branches"`
+
+	// OfferCommitAll prompts to commit all tracked changes (git commit -a)
+	// when the commit flow opens with nothing staged
+	OfferCommitAll bool `toml:"offer_commit_all"`
+
+	// SignCommits signs every commit made from the commit flow and the
+	// commit details (git commit -S). Git's own commit.gpgsign is honored
+	// either way.
+	SignCommits bool `toml:"sign_commits"`
+
+	// DeltaLineStats shows the dashboard's line stats as a net delta
+	// (+12) instead of added/deleted totals (+20/-8). The = key switches
+	// them for the current run without changing this setting.
+	DeltaLineStats bool `toml:"delta_line_stats"`
+
+	// CountTrackedOnly leaves untracked files out of the dashboard's
+	// files changed count
+	CountTrackedOnly bool `toml:"count_tracked_only"`
+
+	// ConfirmQuit asks before quitting while there are uncommitted changes
+	// or a commit message has been typed
+	ConfirmQuit bool `toml:"confirm_quit"`
+
+	// RestoreSession reopens the graph or branch list, with the same commit
+	// or branch selected, when GitGoblin was quit from it in the same
+	// repository. Sessions are kept in state.toml next to this file.
+	RestoreSession bool `toml:"restore_session"`
+
+	// RefreshInterval is how often the dashboard refreshes itself, e.g.
+	// "10s" for large repositories. Zero starts with auto-refresh paused.
+	RefreshInterval time.Duration `toml:"refresh_interval"`
+
+	// AuthorInitials shows each author's initials in the graph instead of
+	// their full name, e.g. "JD" for Jane Doe
+	AuthorInitials bool `toml:"author_initials"`
+
+	// ShowHistoryStats shows the commit, branch, tag and contributor counts
+	// on the dashboard from the start, toggle with #
+	ShowHistoryStats bool `toml:"show_history_stats"`
+
+	// Theme selects the color scheme: "auto" (from $COLORFGBG), "dark"
+	// or "light"
+	Theme string `toml:"theme"`
+
+	// Colors overrides individual theme colors by name, e.g.
+	// accent = "#5f87ff" or border = "250"
+	Colors map[string]string `toml:"colors"`
+
+	// Keys remaps navigation and dashboard keys, see KeyMap
+	Keys KeyMap `toml:"keys"`
+}
+
+// DefaultRefreshInterval is how often the dashboard refreshes unless
+// refresh_interval says otherwise
+const DefaultRefreshInterval = 2 * time.Second
+
+// Default returns the configuration used when no config file exists
+func Default() *Config {
+	return &Config{
+		CommitTrailers:  []string{"Co-authored-by", "Reviewed-by", "Refs", "Closes"},
+		CommitTypes:     []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"},
+		RefreshInterval: DefaultRefreshInterval,
+		Theme:           "auto",
+		Keys:            DefaultKeyMap(),
+	}
+}
+
+// Path returns the location of the config file, honoring $XDG_CONFIG_HOME
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gitgoblin", "config.toml"), nil
+}
+
+// Load reads the config file, falling back to defaults when it is absent
+func Load() (*Config, error) {
+	cfg := Default()
+
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return Default(), fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// IsProtected reports whether branch matches protected_branches, or is
+// defaultBranch when the list is unset
+func (c *Config) IsProtected(branch, defaultBranch string) bool {
+	if branch == "" {
+		return false
+	}
+
+	patterns := c.ProtectedBranches
+	if patterns == nil {
+		patterns = []string{defaultBranch}
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExcluded reports whether a repository-relative path matches one of the
+// exclude patterns. Patterns without a slash match a name in any directory,
+// "**" matches any number of directories, and a matching directory hides
+// everything below it.
+func (c *Config) IsExcluded(path string) bool {
+	segments := strings.Split(strings.TrimSuffix(filepath.ToSlash(path), "/"), "/")
+	for _, pattern := range c.ExcludePaths {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+		if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+		patternSegments := strings.Split(pattern, "/")
+		for i := 1; i <= len(segments); i++ {
+			if matchGlob(patternSegments, segments[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against pattern segments, where a "**"
+// segment consumes zero or more path segments
+func matchGlob(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchGlob(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// KeyBinding lists the keys that trigger an action, named the way Bubble
+// Tea reports them: "j", "G", "down", "ctrl+c", "enter" and so on. The
+// config file accepts a single key or a list, e.g. up = "e" or
+// down = ["n", "down"].
+type KeyBinding []string
+
+// Matches reports whether key is bound to the action
+func (b KeyBinding) Matches(key string) bool {
+	return slices.Contains(b, key)
+}
+
+// Help returns the key shown in hints, the first one bound
+func (b KeyBinding) Help() string {
+	if len(b) == 0 {
+		return ""
+	}
+	return b[0]
+}
+
+// UnmarshalTOML accepts either a single key or a list of keys
+func (b *KeyBinding) UnmarshalTOML(value any) error {
+	switch v := value.(type) {
+	case string:
+		*b = KeyBinding{v}
+	case []any:
+		keys := make(KeyBinding, 0, len(v))
+		for _, item := range v {
+			key, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("key bindings must be strings, got %v", item)
+			}
+			keys = append(keys, key)
+		}
+		*b = keys
+	default:
+		return fmt.Errorf("key bindings must be a string or a list of strings, got %v", value)
+	}
+	return nil
+}
+
+// KeyMap holds the remappable keys, read from the [keys] table of the
+// config file. The default tag lists each action's default keys; actions
+// left out of the file keep them.
+type KeyMap struct {
+	// Navigation in every list and scrollable view
+	Up     KeyBinding `toml:"up" default:"k,up"`
+	Down   KeyBinding `toml:"down" default:"j,down"`
+	Top    KeyBinding `toml:"top" default:"g"`
+	Bottom KeyBinding `toml:"bottom" default:"G"`
+
+	// Dashboard actions
+	NewBranch KeyBinding `toml:"new_branch" default:"n"`
+	Commit    KeyBinding `toml:"commit" default:"c"`
+
+	// Quit works from the dashboard. ctrl+c always quits, from any view,
+	// though confirm_quit may ask first.
+	Quit KeyBinding `toml:"quit" default:"ctrl+c"`
+}
+
+// DefaultKeyMap returns the key map described by the default tags
+func DefaultKeyMap() KeyMap {
+	var keys KeyMap
+	v := reflect.ValueOf(&keys).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("default"); tag != "" {
+			v.Field(i).Set(reflect.ValueOf(KeyBinding(strings.Split(tag, ","))))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Session is where GitGoblin was left in a repository, reopened on the next
+// start when restore_session is set
+type Session struct {
+	View   string `toml:"view"`             // "dashboard", "graph" or "branches"
+	Commit string `toml:"commit,omitempty"` // hash selected in the graph
+	Branch string `toml:"branch,omitempty"` // branch selected in the branch list
+}
+
+// sessionPath returns the location of the state file, next to the config file
+func sessionPath() (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "state.toml"), nil
+}
+
+// loadSessions reads every saved session, keyed by repository root
+func loadSessions() (map[string]Session, error) {
+	sessions := map[string]Session{}
+
+	path, err := sessionPath()
+	if err != nil {
+		return sessions, err
+	}
+
+	if _, err := toml.DecodeFile(path, &sessions); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return sessions, nil
+		}
+		return map[string]Session{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return sessions, nil
+}
+
+// LoadSession returns the session saved for the repository at root, the
+// zero Session when there is none
+func LoadSession(root string) (Session, error) {
+	sessions, err := loadSessions()
+	return sessions[root], err
+}
+
+// SaveSession records the session for the repository at root, keeping
+// those saved for other repositories
+func SaveSession(root string, session Session) error {
+	// An unreadable state file is replaced rather than kept failing
+	sessions, _ := loadSessions()
+	sessions[root] = session
+
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := toml.NewEncoder(file).Encode(sessions); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
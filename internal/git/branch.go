@@ -3,99 +3,96 @@ package git
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
-	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
 
-// GetBranches returns all branches with their info
+// branchFormat is the for-each-ref format parsed by parseBranches:
+// HEAD marker, full ref name, symref target, hash, upstream, tracking,
+// commit date and subject
+const branchFormat = "%(HEAD)%00%(refname)%00%(symref)%00%(objectname)%00%(upstream:short)%00%(upstream:track)%00%(committerdate:unix)%00%(contents:subject)"
+
+// GetBranches returns all local and remote-tracking branches with their info
 func GetBranches() ([]models.Branch, error) {
-	// Get branches with their last commit
-	cmd := exec.Command("git", "branch", "-vv", "--all")
+	cmd := command("for-each-ref", "--format="+branchFormat, "refs/heads", "refs/remotes")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get branches: %w", err)
 	}
 
-	return parseBranches(output)
+	return parseBranches(output), nil
 }
 
-func parseBranches(output []byte) ([]models.Branch, error) {
+func parseBranches(output []byte) []models.Branch {
 	var branches []models.Branch
 	scanner := bufio.NewScanner(bytes.NewReader(output))
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
+		parts := strings.Split(scanner.Text(), "\x00")
+		if len(parts) < 8 {
 			continue
 		}
-
-		branch := models.Branch{}
-
-		// Check if current branch (marked with *)
-		if strings.HasPrefix(line, "*") {
-			branch.IsCurrent = true
-			line = strings.TrimPrefix(line, "*")
-		} else {
-			line = strings.TrimPrefix(line, " ")
-		}
-
-		line = strings.TrimSpace(line)
-
-		// Parse: name hash [upstream] message
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
+		// Skip symbolic refs such as origin/HEAD
+		if parts[2] != "" {
 			continue
 		}
 
-		branch.Name = parts[0]
-		branch.Hash = parts[1]
-
-		// Check if it's a remote branch
-		if strings.HasPrefix(branch.Name, "remotes/") {
+		branch := models.Branch{
+			IsCurrent:  parts[0] == "*",
+			Hash:       parts[3],
+			Upstream:   parts[4],
+			LastCommit: parts[7],
+		}
+		if name, ok := strings.CutPrefix(parts[1], "refs/remotes/"); ok {
+			branch.Name = name
 			branch.IsRemote = true
-			branch.Name = strings.TrimPrefix(branch.Name, "remotes/")
+		} else {
+			branch.Name = strings.TrimPrefix(parts[1], "refs/heads/")
 		}
-
-		// Parse upstream info [origin/main: ahead 2, behind 1]
-		if len(parts) > 2 && strings.HasPrefix(parts[2], "[") {
-			upstreamInfo := extractUpstreamInfo(line)
-			if upstreamInfo != "" {
-				branch.Upstream = upstreamInfo
-			}
+		if branch.Upstream != "" {
+			branch.Ahead, branch.Behind, branch.Gone = parseTracking(parts[5])
 		}
-
-		// Get last commit message (everything after hash and upstream)
-		messageStart := strings.Index(line, branch.Hash) + len(branch.Hash)
-		if messageStart < len(line) {
-			message := line[messageStart:]
-			// Skip upstream info if present
-			if idx := strings.Index(message, "]"); idx > 0 && strings.Contains(message[:idx], "[") {
-				message = message[idx+1:]
-			}
-			branch.LastCommit = strings.TrimSpace(message)
+		if timestamp, err := strconv.ParseInt(parts[6], 10, 64); err == nil {
+			branch.Date = time.Unix(timestamp, 0)
 		}
 
 		branches = append(branches, branch)
 	}
 
-	return branches, nil
+	return branches
 }
 
-func extractUpstreamInfo(line string) string {
-	start := strings.Index(line, "[")
-	end := strings.Index(line, "]")
-	if start >= 0 && end > start {
-		return line[start+1 : end]
+// parseTracking reads the counts from %(upstream:track), such as
+// "[ahead 2, behind 1]" or "[gone]"
+func parseTracking(track string) (ahead, behind int, gone bool) {
+	track = strings.TrimSuffix(strings.TrimPrefix(track, "["), "]")
+	if track == "" {
+		return 0, 0, false
 	}
-	return ""
+
+	for _, part := range strings.Split(track, ", ") {
+		fields := strings.Fields(part)
+		switch {
+		case len(fields) == 1 && fields[0] == "gone":
+			gone = true
+		case len(fields) == 2 && fields[0] == "ahead":
+			ahead, _ = strconv.Atoi(fields[1])
+		case len(fields) == 2 && fields[0] == "behind":
+			behind, _ = strconv.Atoi(fields[1])
+		}
+	}
+	return ahead, behind, gone
 }
 
 // SwitchBranch checks out a different branch
 func SwitchBranch(name string) error {
-	cmd := exec.Command("git", "checkout", name)
+	cmd := command("checkout", name)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to switch branch: %s", string(output))
@@ -103,9 +100,31 @@ func SwitchBranch(name string) error {
 	return nil
 }
 
+// CheckoutTracking creates the branch local from remoteBranch, e.g.
+// "origin/feature", set up to track it, and checks it out
+func CheckoutTracking(local, remoteBranch string) error {
+	cmd := command("checkout", "-b", local, "--track", "remotes/"+remoteBranch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to check out %s: %s", remoteBranch, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CheckoutCommit checks out commit as a detached HEAD, carrying uncommitted
+// changes along when they don't conflict
+func CheckoutCommit(commit string) error {
+	cmd := command("checkout", "--detach", commit)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to check out commit: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // CreateBranch creates a new branch
 func CreateBranch(name string) error {
-	cmd := exec.Command("git", "branch", name)
+	cmd := command("branch", name)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to create branch: %s", string(output))
@@ -113,36 +132,146 @@ func CreateBranch(name string) error {
 	return nil
 }
 
+// ValidateBranchName checks that name is usable as a branch name, the way
+// git check-ref-format --branch does
+func ValidateBranchName(name string) error {
+	cmd := command("check-ref-format", "--branch", name)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%q is not a valid branch name", name)
+	}
+	return nil
+}
+
+// RenameBranch renames a local branch, the current one included. Its
+// upstream configuration moves along but still names the old remote branch.
+func RenameBranch(oldName, newName string) error {
+	if err := ValidateBranchName(newName); err != nil {
+		return err
+	}
+	cmd := command("branch", "-m", oldName, newName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to rename branch: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ErrBranchNotMerged is returned by DeleteBranch when a non-forced delete
+// would lose commits that aren't merged anywhere
+var ErrBranchNotMerged = errors.New("branch is not fully merged")
+
 // DeleteBranch deletes a branch
 func DeleteBranch(name string, force bool) error {
 	flag := "-d"
 	if force {
 		flag = "-D"
 	}
-	cmd := exec.Command("git", "branch", flag, name)
+	cmd := command("branch", flag, name)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if !force && strings.Contains(string(output), "not fully merged") {
+			return fmt.Errorf("%w: %s", ErrBranchNotMerged, name)
+		}
 		return fmt.Errorf("failed to delete branch: %s", string(output))
 	}
 	return nil
 }
 
-// GetDefaultBranch detects the repository's default branch
+// MergeConflictError is returned when a merge stops on conflicts. The merge
+// stays in progress until the result is committed or MergeAbort is called.
+type MergeConflictError struct {
+	Branch string
+	Files  []string // conflicted paths, relative to the repository root
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("merging %s stopped on conflicts in %s", e.Branch, strings.Join(e.Files, ", "))
+}
+
+// Merge merges branch into the current branch, fast-forwarding when
+// possible unless noFF is set
+func Merge(branch string, noFF bool) error {
+	args := []string{"merge", "--no-edit"}
+	if noFF {
+		args = append(args, "--no-ff")
+	}
+	args = append(args, branch)
+
+	cmd := command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if files, _ := ConflictedFiles(); len(files) > 0 {
+			return &MergeConflictError{Branch: branch, Files: files}
+		}
+		return fmt.Errorf("merge failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MergeAbort cancels an in-progress merge, restoring the branch and working
+// tree to where they were before it started
+func MergeAbort() error {
+	cmd := command("merge", "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to abort merge: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// defaultBranchCache holds the detected default branch, as detecting it can
+// take a network round trip through git remote show
+var defaultBranchCache struct {
+	sync.Mutex
+	valid  bool
+	branch string
+}
+
+// GetDefaultBranch returns the repository's default branch. It is detected
+// once and then cached until InvalidateDefaultBranchCache is called. A
+// failed detection isn't cached, e.g. one racing a fetch is tried again on
+// the next call.
 func GetDefaultBranch() (string, error) {
+	defaultBranchCache.Lock()
+	defer defaultBranchCache.Unlock()
+
+	if !defaultBranchCache.valid {
+		branch, err := detectDefaultBranch()
+		if err != nil {
+			return "", err
+		}
+		defaultBranchCache.branch, defaultBranchCache.valid = branch, true
+	}
+	return defaultBranchCache.branch, nil
+}
+
+// InvalidateDefaultBranchCache makes the next GetDefaultBranch detect the
+// default branch again, e.g. after <remote>/HEAD may have moved or the
+// default remote changed
+func InvalidateDefaultBranchCache() {
+	defaultBranchCache.Lock()
+	defaultBranchCache.valid = false
+	defaultBranchCache.Unlock()
+}
+
+// detectDefaultBranch works out the default branch from the default remote
+func detectDefaultBranch() (string, error) {
+	remote := DefaultRemote()
+
 	// Method 1: Try symbolic-ref (fastest, most reliable if set)
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD", "--short")
+	cmd := command("symbolic-ref", "refs/remotes/"+remote+"/HEAD", "--short")
 	output, err := cmd.Output()
 	if err == nil {
 		branchName := strings.TrimSpace(string(output))
-		// Output is like "origin/main", strip "origin/" prefix
-		if strings.HasPrefix(branchName, "origin/") {
-			return strings.TrimPrefix(branchName, "origin/"), nil
+		// Output is like "origin/main", strip the remote prefix
+		if strings.HasPrefix(branchName, remote+"/") {
+			return strings.TrimPrefix(branchName, remote+"/"), nil
 		}
 		return branchName, nil
 	}
 
-	// Method 2: Try git remote show origin
-	cmd = exec.Command("git", "remote", "show", "origin")
+	// Method 2: Try git remote show <remote>
+	cmd = command("remote", "show", remote)
 	output, err = cmd.Output()
 	if err == nil {
 		scanner := bufio.NewScanner(bytes.NewReader(output))
@@ -160,7 +289,7 @@ func GetDefaultBranch() (string, error) {
 	// Method 3: Fallback to common default branch names
 	commonDefaults := []string{"main", "master", "dev", "develop"}
 	for _, branchName := range commonDefaults {
-		cmd = exec.Command("git", "rev-parse", "--verify", "origin/"+branchName)
+		cmd = command("rev-parse", "--verify", remote+"/"+branchName)
 		if err := cmd.Run(); err == nil {
 			return branchName, nil
 		}
@@ -177,14 +306,15 @@ func CreateBranchFromDefault(branchName string) error {
 		return fmt.Errorf("failed to detect default branch: %w", err)
 	}
 
-	// 2. Fetch latest from origin
-	cmd := exec.Command("git", "fetch", "origin", defaultBranch)
+	// 2. Fetch latest from the default remote
+	remote := DefaultRemote()
+	cmd := command("fetch", remote, defaultBranch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to fetch: %s", string(output))
 	}
 
-	// 3. Create and checkout new branch from origin/<default>
-	cmd = exec.Command("git", "checkout", "-b", branchName, "origin/"+defaultBranch)
+	// 3. Create and checkout new branch from <remote>/<default>
+	cmd = command("checkout", "-b", branchName, remote+"/"+defaultBranch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to create branch: %s", string(output))
 	}
@@ -192,12 +322,53 @@ func CreateBranchFromDefault(branchName string) error {
 	return nil
 }
 
+// GetMergeBase returns the best common ancestor commit of a and b
+func GetMergeBase(a, b string) (models.Commit, error) {
+	cmd := command("merge-base", a, b)
+	output, err := cmd.Output()
+	if err != nil {
+		return models.Commit{}, fmt.Errorf("failed to find merge base: %w", err)
+	}
+	hash := strings.TrimSpace(string(output))
+
+	cmd = command("log", "-1", fmt.Sprintf("--pretty=format:%s", commitFormat), hash)
+	output, err = cmd.Output()
+	if err != nil {
+		return models.Commit{}, fmt.Errorf("failed to read merge base commit: %w", err)
+	}
+
+	commits := parseCommits(output)
+	if len(commits) == 0 {
+		return models.Commit{}, fmt.Errorf("failed to parse merge base commit %s", hash)
+	}
+	return commits[0], nil
+}
+
 // GetBranchComparison returns ahead/behind counts compared to the default branch
 func GetBranchComparison(currentBranch, defaultBranch string) (ahead, behind int, err error) {
+	return GetAheadBehind(DefaultRemote() + "/" + defaultBranch)
+}
+
+// GetUpstreamComparison returns how many commits HEAD is ahead of and
+// behind the current branch's upstream. hasUpstream is false, with no
+// error, when there is no upstream or it no longer exists.
+func GetUpstreamComparison() (ahead, behind int, hasUpstream bool, err error) {
+	if err := command("rev-parse", "-q", "--verify", "@{upstream}").Run(); err != nil {
+		return 0, 0, false, nil
+	}
+	ahead, behind, err = GetAheadBehind("@{upstream}")
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return ahead, behind, true, nil
+}
+
+// GetAheadBehind returns how many commits HEAD is ahead of and behind base
+func GetAheadBehind(base string) (ahead, behind int, err error) {
 	// Use git rev-list --left-right --count to get both values efficiently
-	// Format: origin/<default>...HEAD
-	target := fmt.Sprintf("origin/%s...HEAD", defaultBranch)
-	cmd := exec.Command("git", "rev-list", "--left-right", "--count", target)
+	// Format: <base>...HEAD
+	target := fmt.Sprintf("%s...HEAD", base)
+	cmd := command("rev-list", "--left-right", "--count", target)
 	output, cmdErr := cmd.Output()
 	if cmdErr != nil {
 		return 0, 0, fmt.Errorf("failed to compare branches: %w", cmdErr)
@@ -209,10 +380,58 @@ func GetBranchComparison(currentBranch, defaultBranch string) (ahead, behind int
 		return 0, 0, fmt.Errorf("unexpected git rev-list output format")
 	}
 
-	// First number is commits in default branch not in current (behind)
-	// Second number is commits in current branch not in default (ahead)
+	// First number is commits in base not in HEAD (behind)
+	// Second number is commits in HEAD not in base (ahead)
 	fmt.Sscanf(parts[0], "%d", &behind)
 	fmt.Sscanf(parts[1], "%d", &ahead)
 
 	return ahead, behind, nil
 }
+
+// GetBranchDiffFiles lists the files HEAD changed since it forked from
+// base, i.e. what a pull request into base would contain
+func GetBranchDiffFiles(base string) ([]models.FileChange, error) {
+	cmd := command("diff", "--name-status", "-M", "-z", base+"...HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	return parseNameStatus(output), nil
+}
+
+// parseNameStatus parses git diff --name-status -z output, where renames
+// and copies are followed by both their old and new path
+func parseNameStatus(output []byte) []models.FileChange {
+	var files []models.FileChange
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+
+	for i := 0; i < len(fields); i++ {
+		// Status letter, with a similarity score for renames, e.g. "R087"
+		status := fields[i]
+		if status == "" || i+1 >= len(fields) {
+			continue
+		}
+		file := models.FileChange{Status: models.FileStatus(status[:1])}
+		if (status[0] == 'R' || status[0] == 'C') && i+2 < len(fields) {
+			file.OldPath = fields[i+1]
+			i++
+		}
+		file.Path = fields[i+1]
+		i++
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// GetBranchDiff returns the diff of paths between the point HEAD forked
+// from base and HEAD. Pass both paths of a rename to diff it as one.
+func GetBranchDiff(base string, paths ...string) (string, error) {
+	args := append([]string{"diff", "-M", base + "...HEAD", "--"}, paths...)
+	cmd := command(args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	return string(output), nil
+}
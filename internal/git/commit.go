@@ -0,0 +1,395 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9-]+: `)
+
+// BuildCommitMessage appends trailers to a commit message. If the message
+// already ends in a trailer block the new trailers join it, otherwise a new
+// block is started after a blank line.
+func BuildCommitMessage(message string, trailers []models.Trailer) string {
+	message = strings.TrimRight(message, "\n ")
+	if len(trailers) == 0 {
+		return message
+	}
+
+	lines := make([]string, 0, len(trailers))
+	for _, t := range trailers {
+		lines = append(lines, t.Key+": "+strings.TrimSpace(t.Value))
+	}
+	block := strings.Join(lines, "\n")
+
+	if hasTrailerBlock(message) {
+		return message + "\n" + block
+	}
+	return message + "\n\n" + block
+}
+
+// hasTrailerBlock reports whether the last paragraph of a message (other
+// than the subject) consists only of trailer lines
+func hasTrailerBlock(message string) bool {
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		return false
+	}
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// messageArgs passes a message's subject and body as separate -m flags,
+// which git joins with a blank line
+func messageArgs(message string) []string {
+	subject, body, _ := strings.Cut(message, "\n")
+	args := []string{"-m", subject}
+	if body = strings.Trim(body, "\n"); body != "" {
+		args = append(args, "-m", body)
+	}
+	return args
+}
+
+// CommitOptions holds the flags shared by Commit, CommitAll and CommitAmend
+type CommitOptions struct {
+	Sign     bool // sign with -S, on top of git's own commit.gpgsign
+	NoVerify bool // skip the pre-commit and commit-msg hooks
+}
+
+func (o CommitOptions) args() []string {
+	var args []string
+	if o.Sign {
+		args = append(args, "-S")
+	}
+	if o.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	return args
+}
+
+// CommitAll commits all modified tracked files, like git commit -a
+func CommitAll(message string, opts CommitOptions) error {
+	return runCommit("commit", append([]string{"-a"}, messageArgs(message)...), opts)
+}
+
+// CommitAmend amends the last commit with the staged changes. An empty
+// message keeps the previous one.
+func CommitAmend(message string, opts CommitOptions) error {
+	args := []string{"--amend"}
+	if message == "" {
+		args = append(args, "--no-edit")
+	} else {
+		args = append(args, messageArgs(message)...)
+	}
+	return runCommit("amend", args, opts)
+}
+
+// CommitEmpty creates a commit even when nothing is staged, e.g. to mark a
+// milestone. Anything that is staged still goes in.
+func CommitEmpty(message string, opts CommitOptions) error {
+	return runCommit("commit", append([]string{"--allow-empty"}, messageArgs(message)...), opts)
+}
+
+// AmendAuthor rewrites the author of the HEAD commit, keeping its message,
+// content and author date. Staged changes are left out of it. Hooks are
+// skipped whatever opts.NoVerify says.
+func AmendAuthor(name, email string, opts CommitOptions) error {
+	author := fmt.Sprintf("--author=%s <%s>", name, email)
+	// Only the author changes, so there is nothing new for pre-commit to
+	// check, and --allow-empty keeps git from refusing an empty HEAD
+	args := []string{"--amend", "--only", "--allow-empty", "--no-edit", author}
+	opts.NoVerify = true
+	return runCommit("amend", args, opts)
+}
+
+// CommitFixup commits the staged changes as a fixup of an earlier commit,
+// for git rebase --autosquash to fold into it later
+func CommitFixup(targetHash string, opts CommitOptions) error {
+	// git diff --cached --quiet exits 0 when nothing is staged
+	if err := command("diff", "--cached", "--quiet").Run(); err == nil {
+		return fmt.Errorf("nothing staged to fix up %s with", targetHash)
+	}
+	return runCommit("fixup", []string{"--fixup=" + targetHash}, opts)
+}
+
+// GetAuthorIdent returns the "Name <email>" git uses for new commits
+func GetAuthorIdent() (string, error) {
+	cmd := command("var", "GIT_AUTHOR_IDENT")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get author identity: %w", err)
+	}
+	// Drop the timestamp and zone that follow the email
+	ident := strings.TrimSpace(string(output))
+	if i := strings.LastIndex(ident, ">"); i != -1 {
+		ident = ident[:i+1]
+	}
+	return ident, nil
+}
+
+// ErrSigningFailed is returned when git couldn't sign a commit, e.g. for a
+// wrong passphrase or a missing key. The commit is not made.
+var ErrSigningFailed = errors.New("failed to sign the commit")
+
+// HookError is returned when a commit hook rejects a commit. Output holds
+// everything the hook printed, e.g. the linter messages that failed it.
+type HookError struct {
+	Hook   string // e.g. "pre-commit hook", or "commit hook" when it can't be told which
+	Output string
+}
+
+func (e *HookError) Error() string {
+	return e.Hook + " rejected the commit"
+}
+
+// runCommit runs git commit with args and opts. git signs on its own when
+// commit.gpgsign is set, either way a hook rejecting the commit is reported
+// as a HookError, and a signing failure as ErrSigningFailed with git's
+// explanation.
+func runCommit(action string, args []string, opts CommitOptions) error {
+	cmd := command(append(append([]string{"commit"}, opts.args()...), args...)...)
+	cmd.Env = signingEnv()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// A failing hook's output can be anything, including words gpg uses
+		if hook := rejectingHook(string(output), opts.NoVerify); hook != "" {
+			return &HookError{Hook: hook, Output: strings.TrimRight(string(output), "\n")}
+		}
+		if isSigningFailure(string(output), opts.Sign || SignsCommits()) {
+			return fmt.Errorf("%w: %s", ErrSigningFailed, strings.TrimSpace(string(output)))
+		}
+		return fmt.Errorf("%s failed: %s", action, string(output))
+	}
+	return nil
+}
+
+// commitHooks are the hooks that can reject a commit, in the order git runs
+// them. --no-verify skips all but prepare-commit-msg.
+var commitHooks = []string{"pre-commit", "prepare-commit-msg", "commit-msg"}
+
+// rejectingHook names the hook that most likely failed a commit with
+// output, or returns "" if the failure came from git itself. git adds no
+// message of its own when a hook fails, so any installed hook is blamed
+// unless git's last line is an error.
+func rejectingHook(output string, noVerify bool) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	last := lines[len(lines)-1]
+	if strings.HasPrefix(last, "fatal: ") || strings.HasPrefix(last, "error: ") {
+		return ""
+	}
+	// "nothing to commit" and friends come from git on stdout
+	if strings.Contains(output, "nothing to commit") || strings.Contains(output, "no changes added to commit") {
+		return ""
+	}
+
+	dir, err := command("rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return ""
+	}
+	hooksDir := repoPath(strings.TrimSpace(string(dir)))
+
+	var installed []string
+	for _, hook := range commitHooks {
+		if noVerify && hook != "prepare-commit-msg" {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(hooksDir, hook))
+		if err == nil && info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+			installed = append(installed, hook)
+		}
+	}
+	switch len(installed) {
+	case 0:
+		return ""
+	case 1:
+		return installed[0] + " hook"
+	}
+	return "commit hook"
+}
+
+// isSigningFailure reports whether a failed commit's output comes from gpg,
+// gpgsm or ssh-keygen refusing to sign. Only git's own message counts
+// unless the commit was being signed, when the signing programs' own
+// messages, and git giving up on writing the commit, count too.
+func isSigningFailure(output string, signing bool) bool {
+	if strings.Contains(output, "error: gpg failed to sign the data") {
+		return true
+	}
+	if !signing {
+		return false
+	}
+	for _, marker := range []string{"fatal: failed to write commit object", "gpg:", "gpgsm:", "ssh-keygen", "Load key", "Couldn't load", "passphrase"} {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// signingEnv points gpg's pinentry at the terminal when GPG_TTY isn't set,
+// so a passphrase prompt can appear while a commit runs in the foreground.
+// ssh-keygen and graphical pinentries need nothing beyond the inherited
+// environment.
+func signingEnv() []string {
+	env := os.Environ()
+	if os.Getenv("GPG_TTY") != "" {
+		return env
+	}
+	tty := exec.Command("tty")
+	tty.Stdin = os.Stdin
+	if output, err := tty.Output(); err == nil {
+		env = append(env, "GPG_TTY="+strings.TrimSpace(string(output)))
+	}
+	return env
+}
+
+// SignsCommits reports whether commit.gpgsign makes git sign every commit
+func SignsCommits() bool {
+	output, err := command("config", "--get", "--type=bool", "commit.gpgsign").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// GetCoreEditor returns the editor set in core.editor, or "" when there is none
+func GetCoreEditor() string {
+	output, err := command("config", "--get", "core.editor").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GetLastCommitMessage returns the full message of the HEAD commit
+func GetLastCommitMessage() (string, error) {
+	cmd := command("log", "-1", "--format=%B")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get last commit message: %w", err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// GetCommitTemplate returns the file named by commit.template with its
+// comment lines removed, or "" when no template is configured
+func GetCommitTemplate() (string, error) {
+	cmd := command("config", "--get", "--type=path", "commit.template")
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means the key isn't set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read commit.template: %w", err)
+	}
+
+	// git resolves relative paths from the top of the working tree
+	path := repoPath(strings.TrimSpace(string(output)))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit template: %w", err)
+	}
+
+	// Comments would survive git commit -m, so drop them here
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimSuffix(line, "\r"))
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n"), nil
+}
+
+// ErrCherryPickConflict is returned when a cherry-pick stops on conflicts.
+// The cherry-pick stays in progress until CherryPickContinue or
+// CherryPickAbort.
+var ErrCherryPickConflict = errors.New("cherry-pick stopped on conflicts")
+
+// CherryPick applies the changes of a commit on top of the current branch
+func CherryPick(hash string) error {
+	cmd := command("cherry-pick", hash)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if hasUnmergedFiles() {
+			return fmt.Errorf("%w, resolve them and continue or abort", ErrCherryPickConflict)
+		}
+		return fmt.Errorf("cherry-pick failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CherryPickContinue commits the resolved cherry-pick with the original
+// commit's message
+func CherryPickContinue() error {
+	cmd := command("cherry-pick", "--continue")
+	// Keep the message as is rather than opening an editor
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to continue cherry-pick: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CherryPickAbort cancels an in-progress cherry-pick, restoring the branch
+// to where it was before
+func CherryPickAbort() error {
+	cmd := command("cherry-pick", "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to abort cherry-pick: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ErrRevertConflict is returned when a revert stops on conflicts. The
+// revert stays in progress until RevertContinue or RevertAbort.
+var ErrRevertConflict = errors.New("revert stopped on conflicts")
+
+// Revert commits the inverse of a commit's changes on top of the current
+// branch, with git's default "Revert ..." message
+func Revert(hash string) error {
+	cmd := command("revert", "--no-edit", hash)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if hasUnmergedFiles() {
+			return fmt.Errorf("%w, resolve them and continue or abort", ErrRevertConflict)
+		}
+		return fmt.Errorf("revert failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RevertContinue commits the resolved revert with its default message
+func RevertContinue() error {
+	cmd := command("revert", "--continue")
+	// Keep the message as is rather than opening an editor
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to continue revert: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RevertAbort cancels an in-progress revert, restoring the branch to where
+// it was before
+func RevertAbort() error {
+	cmd := command("revert", "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to abort revert: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseHunks splits the output of GetDiff into its hunks
+func ParseHunks(diff string) []models.Hunk {
+	var hunks []models.Hunk
+	var header []string
+	var current *models.Hunk
+
+	// Split on \n only so CRLF content keeps its \r and the patch still applies
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+			header = []string{line}
+			continue
+		}
+
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			if current != nil {
+				hunks = append(hunks, *current)
+			}
+			current = &models.Hunk{
+				FileHeader: strings.Join(header, "\n"),
+				Header:     line,
+				OldStart:   atoi(m[1]),
+				OldLines:   hunkCount(m[2]),
+				NewStart:   atoi(m[3]),
+				NewLines:   hunkCount(m[4]),
+			}
+			continue
+		}
+
+		if current != nil {
+			current.Lines = append(current.Lines, line)
+		} else {
+			header = append(header, line)
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	return hunks
+}
+
+// StageHunk stages a single hunk of an unstaged file diff
+func StageHunk(path string, hunk models.Hunk) error {
+	return applyHunk(path, hunk, false)
+}
+
+// UnstageHunk removes a single hunk of a staged file diff from the index
+func UnstageHunk(path string, hunk models.Hunk) error {
+	return applyHunk(path, hunk, true)
+}
+
+func applyHunk(path string, hunk models.Hunk, reverse bool) error {
+	args := []string{"apply", "--cached", "--whitespace=nowarn"}
+	if reverse {
+		args = append(args, "--reverse")
+	}
+	args = append(args, "-")
+
+	cmd := command(args...)
+	cmd.Stdin = strings.NewReader(hunkPatch(path, hunk))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to apply hunk: %s", string(output))
+	}
+	return nil
+}
+
+// GetStagedPatch returns the staged changes to path as a patch that
+// ApplyStagedPatch can put back in the index later
+func GetStagedPatch(path string) (string, error) {
+	cmd := command("--literal-pathspecs", "diff", "--cached", "--binary", "--", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged changes to %s: %w", path, err)
+	}
+	return string(output), nil
+}
+
+// ApplyStagedPatch applies a patch to the index only, leaving the working
+// tree as it is
+func ApplyStagedPatch(patch string) error {
+	cmd := command("apply", "--cached", "--whitespace=nowarn", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to apply staged changes: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// hunkPatch builds a patch containing only the given hunk
+func hunkPatch(path string, hunk models.Hunk) string {
+	var b strings.Builder
+
+	header := hunk.FileHeader
+	if header == "" {
+		header = fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s", path, path, path, path)
+	}
+	b.WriteString(header + "\n")
+	b.WriteString(hunk.Header + "\n")
+	for _, line := range hunk.Lines {
+		// Keeps "\ No newline at end of file" markers right after their line
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// hunkCount parses an optional hunk line count, which defaults to 1
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
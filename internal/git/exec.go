@@ -0,0 +1,27 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runWithContext runs a git command that can be cancelled or time out via
+// ctx, returning its combined output
+func runWithContext(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = current.Root
+	output, err := cmd.CombinedOutput()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return output, fmt.Errorf("git %s timed out", args[0])
+		}
+		return output, fmt.Errorf("git %s cancelled", args[0])
+	}
+	if err != nil {
+		return output, fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
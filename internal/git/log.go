@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -12,51 +11,171 @@ import (
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
 
+// LogOptions narrows down which commits GetCommitsWithOptions returns
+type LogOptions struct {
+	Limit  int
+	Author string // case-insensitive substring of the author name or email
+	Since  string // git date expression, e.g. "2 weeks ago" or "2024-01-31"
+	Until  string
+	Path   string // only commits touching this file, following renames
+
+	AllRefs bool // every branch and tag instead of HEAD's history only
+	Remotes bool // with AllRefs, remote-tracking branches too
+}
+
+// refs returns the revisions the graph is drawn from
+func (o LogOptions) refs() []string {
+	switch {
+	case o.AllRefs && o.Remotes:
+		return []string{"--all"}
+	case o.AllRefs:
+		// HEAD too, in case it is detached
+		return []string{"--branches", "--tags", "HEAD"}
+	}
+	return []string{"HEAD"}
+}
+
+// args returns the git log flags for the options
+func (o LogOptions) args() []string {
+	var args []string
+	if o.Author != "" {
+		args = append(args, "--author="+o.Author, "--fixed-strings", "--regexp-ignore-case")
+	}
+	if o.Since != "" {
+		args = append(args, "--since="+o.Since)
+	}
+	if o.Until != "" {
+		args = append(args, "--until="+o.Until)
+	}
+	if o.Limit > 0 {
+		args = append(args, fmt.Sprintf("-%d", o.Limit))
+	}
+	if o.Path != "" {
+		// After -- so a path starting with a dash isn't read as a flag
+		args = append(args, "--follow", "--", o.Path)
+	}
+	return args
+}
+
+// ValidateDate checks that git understands a date expression such as
+// "yesterday" or "3 days ago". git log quietly treats unknown expressions
+// as now, so they are parsed strictly through git config instead.
+func ValidateDate(expr string) error {
+	cmd := command("-c", "gitgoblin.date="+expr, "config", "--type=expiry-date", "gitgoblin.date")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("invalid date: %s", expr)
+	}
+	return nil
+}
+
+// commitFormat is the --pretty format parsed by parseCommits: hash, short
+// hash, author, email, date, committer, committer email, commit date, refs,
+// parents and subject, separated by the unit separator, which unlike "|"
+// can't appear in a name
+const commitFormat = "%H%x1f%h%x1f%an%x1f%ae%x1f%at%x1f%cn%x1f%ce%x1f%ct%x1f%D%x1f%P%x1f%s"
+
 // GetCommits retrieves the commit history with graph information
 func GetCommits(limit int) ([]models.Commit, []string, error) {
-	// Format: hash|short|author|email|date|refs|parents|message
-	format := "%H|%h|%an|%ae|%at|%D|%P|%s"
+	return GetCommitsWithOptions(LogOptions{Limit: limit})
+}
+
+// GetCommitsWithOptions retrieves the commit history matching opts with graph information
+func GetCommitsWithOptions(opts LogOptions) ([]models.Commit, []string, error) {
+	commits, graphLines, _, err := GetCommitsPaged(opts, 0, opts.Limit)
+	return commits, graphLines, err
+}
 
+// GetCommitsPaged returns up to limit commits matching opts after skipping
+// the first skip, each with the graph drawn to the left of it, and whether
+// more commits follow. opts.Limit is ignored.
+//
+// The graph is computed over the skipped commits too rather than using
+// --skip, which would restart the graph's lanes at the page boundary, so
+// pages line up when appended to each other.
+func GetCommitsPaged(opts LogOptions, skip, limit int) ([]models.Commit, []string, bool, error) {
+	opts.Limit = 0
 	args := []string{
 		"log",
-		fmt.Sprintf("--pretty=format:%s", format),
-		"--all",
+		"--graph",
+		// A leading unit separator marks where the graph ends and the commit starts
+		fmt.Sprintf("--pretty=format:%%x1f%s", commitFormat),
 		"--date-order",
 	}
-
+	if opts.Path == "" {
+		args = append(args, opts.refs()...)
+	} else {
+		// A file's history follows it from HEAD whatever the refs options
+		args = append([]string{"--literal-pathspecs"}, args...)
+	}
 	if limit > 0 {
-		args = append(args, fmt.Sprintf("-%d", limit))
+		// One extra commit tells whether there is another page
+		args = append(args, fmt.Sprintf("-%d", skip+limit+1))
 	}
+	args = append(args, opts.args()...)
 
-	cmd := exec.Command("git", args...)
+	cmd := command(args...)
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to run git log: %w", err)
+		return nil, nil, false, fmt.Errorf("failed to run git log: %w", err)
 	}
 
-	// Get graph lines separately
-	graphArgs := []string{
-		"log",
-		"--graph",
-		"--oneline",
-		"--all",
-		"--date-order",
+	commits, graphLines := parseGraphLog(output)
+
+	more := false
+	if limit > 0 && len(commits) > skip+limit {
+		commits, graphLines = commits[:skip+limit], graphLines[:skip+limit]
+		more = true
 	}
+	if skip >= len(commits) {
+		return nil, nil, more, nil
+	}
+	return commits[skip:], graphLines[skip:], more, nil
+}
 
-	if limit > 0 {
-		graphArgs = append(graphArgs, fmt.Sprintf("-%d", limit))
+// GetFileHistory returns up to limit commits touching path, newest first,
+// following it back across renames
+func GetFileHistory(path string, limit int) ([]models.Commit, error) {
+	commits, _, _, err := GetCommitsPaged(LogOptions{Path: path}, 0, limit)
+	return commits, err
+}
+
+// parseGraphLog splits git log --graph output into commits and the graph
+// drawn on each commit's line. Connector-only lines between commits are
+// dropped so the two slices stay index-aligned.
+func parseGraphLog(output []byte) ([]models.Commit, []string) {
+	var commits []models.Commit
+	var graphLines []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+		graph, entry, found := strings.Cut(scanner.Text(), "\x1f")
+		if !found {
+			continue
+		}
+		parsed := parseCommits([]byte(entry))
+		if len(parsed) == 0 {
+			continue
+		}
+		commits = append(commits, parsed[0])
+		graphLines = append(graphLines, graph)
 	}
 
-	graphCmd := exec.Command("git", graphArgs...)
-	graphOutput, err := graphCmd.Output()
+	return commits, graphLines
+}
+
+// GetLastCommit returns the commit HEAD points at
+func GetLastCommit() (models.Commit, error) {
+	cmd := command("log", "-1", fmt.Sprintf("--pretty=format:%s", commitFormat), "HEAD")
+	output, err := cmd.Output()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to run git log --graph: %w", err)
+		return models.Commit{}, fmt.Errorf("failed to get last commit: %w", err)
 	}
 
 	commits := parseCommits(output)
-	graphLines := parseGraphLines(graphOutput)
-
-	return commits, graphLines, nil
+	if len(commits) == 0 {
+		return models.Commit{}, fmt.Errorf("no commits found")
+	}
+	return commits[0], nil
 }
 
 func parseCommits(output []byte) []models.Commit {
@@ -65,17 +184,20 @@ func parseCommits(output []byte) []models.Commit {
 
 	for scanner.Scan() {
 		line := scanner.Text()
-		parts := strings.Split(line, "|")
-		if len(parts) < 8 {
+		parts := strings.SplitN(line, "\x1f", 11)
+		if len(parts) < 11 {
 			continue
 		}
 
 		unixTime, _ := strconv.ParseInt(parts[4], 10, 64)
 		timestamp := time.Unix(unixTime, 0)
 
+		commitUnixTime, _ := strconv.ParseInt(parts[7], 10, 64)
+		commitTimestamp := time.Unix(commitUnixTime, 0)
+
 		refs := []string{}
-		if parts[5] != "" {
-			refParts := strings.Split(parts[5], ", ")
+		if parts[8] != "" {
+			refParts := strings.Split(parts[8], ", ")
 			for _, ref := range refParts {
 				ref = strings.TrimSpace(ref)
 				if ref != "" {
@@ -85,19 +207,22 @@ func parseCommits(output []byte) []models.Commit {
 		}
 
 		parents := []string{}
-		if parts[6] != "" {
-			parents = strings.Fields(parts[6])
+		if parts[9] != "" {
+			parents = strings.Fields(parts[9])
 		}
 
 		commit := models.Commit{
-			Hash:      parts[0],
-			ShortHash: parts[1],
-			Author:    parts[2],
-			Email:     parts[3],
-			Date:      timestamp,
-			Refs:      refs,
-			Parents:   parents,
-			Message:   parts[7],
+			Hash:           parts[0],
+			ShortHash:      parts[1],
+			Author:         parts[2],
+			Email:          parts[3],
+			Date:           timestamp,
+			Committer:      parts[5],
+			CommitterEmail: parts[6],
+			CommitDate:     commitTimestamp,
+			Refs:           refs,
+			Parents:        parents,
+			Message:        parts[10],
 		}
 
 		commits = append(commits, commit)
@@ -106,20 +231,9 @@ func parseCommits(output []byte) []models.Commit {
 	return commits
 }
 
-func parseGraphLines(output []byte) []string {
-	var lines []string
-	scanner := bufio.NewScanner(bytes.NewReader(output))
-
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-
-	return lines
-}
-
 // GetCurrentBranch returns the name of the current branch
 func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
+	cmd := command("branch", "--show-current")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
@@ -127,33 +241,52 @@ func GetCurrentBranch() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetHead returns the current branch, or the short hash of HEAD with
+// detached set when no branch is checked out
+func GetHead() (name string, detached bool, err error) {
+	branch, err := GetCurrentBranch()
+	if err != nil {
+		return "", false, err
+	}
+	if branch != "" {
+		name, detached = parseHead(branch, "")
+		return name, detached, nil
+	}
+
+	cmd := command("rev-parse", "--short", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	name, detached = parseHead(branch, string(output))
+	return name, detached, nil
+}
+
+// parseHead interprets the output of branch --show-current and, for a
+// detached HEAD, of rev-parse --short HEAD. branch --show-current prints
+// nothing on a detached HEAD.
+func parseHead(branchOut, shortOut string) (name string, detached bool) {
+	if branch := strings.TrimSpace(branchOut); branch != "" {
+		return branch, false
+	}
+	return strings.TrimSpace(shortOut), true
+}
+
 // GetRepoName returns the repository name from the remote URL or directory
 func GetRepoName() (string, error) {
-	// Try to get from remote URL first
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	output, err := cmd.Output()
-	if err == nil {
-		url := strings.TrimSpace(string(output))
-		// Extract repo name from URL (handles both HTTPS and SSH formats)
-		// https://github.com/user/repo.git -> repo
-		// git@github.com:user/repo.git -> repo
-		url = strings.TrimSuffix(url, ".git")
-		parts := strings.Split(url, "/")
-		if len(parts) > 0 {
-			name := parts[len(parts)-1]
-			// Handle SSH format with colon
-			if colonIdx := strings.LastIndex(name, ":"); colonIdx != -1 {
-				name = name[colonIdx+1:]
-			}
-			if name != "" {
+	// Try to get from remote URL first (handles both HTTPS and SSH formats)
+	if remoteURL, err := GetRemoteURL(DefaultRemote()); err == nil {
+		if _, path, ok := parseRemoteURL(remoteURL); ok {
+			parts := strings.Split(path, "/")
+			if name := parts[len(parts)-1]; name != "" {
 				return name, nil
 			}
 		}
 	}
 
 	// Fallback to directory name
-	cmd = exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err = cmd.Output()
+	cmd := command("rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get repo name: %w", err)
 	}
@@ -167,7 +300,7 @@ func GetRepoName() (string, error) {
 
 // GetStatus returns a simple status of the repo
 func GetStatus() (string, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
+	cmd := command("status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get status: %w", err)
@@ -180,3 +313,122 @@ func GetStatus() (string, error) {
 
 	return fmt.Sprintf("%d changes", len(lines)), nil
 }
+
+// GetCommitDetail returns the full message, per-file stats and diff of a commit
+func GetCommitDetail(hash string) (models.CommitDetail, error) {
+	cmd := command("show", "-s", fmt.Sprintf("--pretty=format:%s", commitFormat), hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return models.CommitDetail{}, fmt.Errorf("failed to show commit: %w", err)
+	}
+	commits := parseCommits(output)
+	if len(commits) == 0 {
+		return models.CommitDetail{}, fmt.Errorf("failed to parse commit %s", hash)
+	}
+
+	cmd = command("show", "-s", "--format=%B", hash)
+	body, err := cmd.Output()
+	if err != nil {
+		return models.CommitDetail{}, fmt.Errorf("failed to get commit message: %w", err)
+	}
+
+	// --numstat lines come first, followed by the patch. Merges are shown
+	// against their first parent, so the file list and diff agree and a clean
+	// merge still lists what it brought in.
+	cmd = command("show", "-m", "--first-parent", "--numstat", "--patch", "--format=", hash)
+	output, err = cmd.Output()
+	if err != nil {
+		return models.CommitDetail{}, fmt.Errorf("failed to get commit diff: %w", err)
+	}
+	files, diff := parseNumstatPatch(string(output))
+
+	return models.CommitDetail{
+		Commit: commits[0],
+		Body:   strings.TrimRight(string(body), "\n"),
+		Files:  files,
+		Diff:   diff,
+	}, nil
+}
+
+// GetHistoryStats counts the commits and contributors in HEAD's history and
+// the local branches and tags. Counting walks the whole history, so it is
+// meant to run on demand rather than on every refresh.
+func GetHistoryStats() (models.HistoryStats, error) {
+	var stats models.HistoryStats
+
+	count := func(what string, args ...string) (int, error) {
+		output, err := command(args...).Output()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count %s: %w", what, err)
+		}
+		return len(strings.Fields(strings.TrimSpace(string(output)))), nil
+	}
+
+	var err error
+	if stats.Branches, err = count("branches", "for-each-ref", "--format=x", "refs/heads"); err != nil {
+		return stats, err
+	}
+	if stats.Tags, err = count("tags", "for-each-ref", "--format=x", "refs/tags"); err != nil {
+		return stats, err
+	}
+
+	// An unborn branch has no history to count
+	if empty, _ := IsEmptyRepo(); empty {
+		return stats, nil
+	}
+
+	output, err := command("rev-list", "--count", "HEAD").Output()
+	if err != nil {
+		return stats, fmt.Errorf("failed to count commits: %w", err)
+	}
+	stats.Commits, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+
+	// shortlog reads a log from stdin unless given a revision
+	output, err = command("shortlog", "-sn", "HEAD").Output()
+	if err != nil {
+		return stats, fmt.Errorf("failed to count contributors: %w", err)
+	}
+	stats.Contributors = len(strings.Split(strings.TrimSpace(string(output)), "\n"))
+
+	return stats, nil
+}
+
+// GetCommitSubject returns the subject line of a commit
+func GetCommitSubject(hash string) (string, error) {
+	cmd := command("log", "-1", "--format=%s", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit subject: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// parseNumstatPatch splits git show --numstat --patch output into file
+// stats and the diff that follows them
+func parseNumstatPatch(output string) ([]models.FileStat, string) {
+	var files []models.FileStat
+
+	diff := ""
+	stats := output
+	if idx := strings.Index(output, "diff --git "); idx != -1 {
+		stats, diff = output[:idx], output[idx:]
+	}
+
+	for _, line := range strings.Split(stats, "\n") {
+		// Format: added<TAB>deleted<TAB>path, "-" counts for binary files
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		file := models.FileStat{Path: unquotePath(parts[2])}
+		if parts[0] == "-" && parts[1] == "-" {
+			file.Binary = true
+		} else {
+			file.Added, _ = strconv.Atoi(parts[0])
+			file.Deleted, _ = strconv.Atoi(parts[1])
+		}
+		files = append(files, file)
+	}
+
+	return files, diff
+}
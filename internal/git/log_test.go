@@ -0,0 +1,81 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+// commitRecord joins fields the way commitFormat has git print them
+func commitRecord(fields ...string) string {
+	return strings.Join(fields, "\x1f")
+}
+
+func TestParseCommits(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  string
+		author  string
+		message string
+	}{
+		{
+			name:    "subject",
+			record:  commitRecord("abc123", "abc", "Ada", "ada@example.com", "1700000000", "Ada", "ada@example.com", "1700000000", "HEAD -> main", "def456", "Add parser"),
+			author:  "Ada",
+			message: "Add parser",
+		},
+		{
+			name:    "empty subject",
+			record:  commitRecord("abc123", "abc", "Ada", "ada@example.com", "1700000000", "Ada", "ada@example.com", "1700000000", "", "def456", ""),
+			author:  "Ada",
+			message: "",
+		},
+		{
+			name:    "pipes in names and subject",
+			record:  commitRecord("abc123", "abc", "Ada | Team", "ada@example.com", "1700000000", "CI|Bot", "ci@example.com", "1700000000", "", "", "Use a | b"),
+			author:  "Ada | Team",
+			message: "Use a | b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits := parseCommits([]byte(tt.record))
+			if len(commits) != 1 {
+				t.Fatalf("got %d commits, want 1", len(commits))
+			}
+			commit := commits[0]
+			if commit.Hash != "abc123" {
+				t.Errorf("Hash = %q, want %q", commit.Hash, "abc123")
+			}
+			if commit.Author != tt.author {
+				t.Errorf("Author = %q, want %q", commit.Author, tt.author)
+			}
+			if commit.Message != tt.message {
+				t.Errorf("Message = %q, want %q", commit.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseHead(t *testing.T) {
+	tests := []struct {
+		name     string
+		branch   string
+		short    string
+		want     string
+		detached bool
+	}{
+		{name: "branch", branch: "main\n", want: "main"},
+		{name: "detached", branch: "", short: "abc1234\n", want: "abc1234", detached: true},
+		{name: "detached, whitespace only", branch: "\n", short: "abc1234\n", want: "abc1234", detached: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, detached := parseHead(tt.branch, tt.short)
+			if name != tt.want || detached != tt.detached {
+				t.Errorf("parseHead(%q, %q) = %q, %v, want %q, %v", tt.branch, tt.short, name, detached, tt.want, tt.detached)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+// MaintenanceTimeout bounds how long gc or maintenance may run
+const MaintenanceTimeout = 10 * time.Minute
+
+// RunGC runs git gc to pack loose objects and prune unreachable ones
+func RunGC() error {
+	ctx, cancel := context.WithTimeout(context.Background(), MaintenanceTimeout)
+	defer cancel()
+	return RunGCContext(ctx)
+}
+
+// RunGCContext runs git gc, stopping early if ctx is cancelled
+func RunGCContext(ctx context.Context) error {
+	_, err := runWithContext(ctx, "gc", "--quiet")
+	return err
+}
+
+// RunMaintenance runs the git maintenance tasks enabled for the repository
+func RunMaintenance() error {
+	ctx, cancel := context.WithTimeout(context.Background(), MaintenanceTimeout)
+	defer cancel()
+	return RunMaintenanceContext(ctx)
+}
+
+// RunMaintenanceContext runs git maintenance, stopping early if ctx is cancelled
+func RunMaintenanceContext(ctx context.Context) error {
+	_, err := runWithContext(ctx, "maintenance", "run", "--quiet")
+	return err
+}
+
+// GetGitDirSize returns the total size in bytes of the repository's .git directory
+func GetGitDirSize() (int64, error) {
+	cmd := command("rev-parse", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	gitDir := repoPath(strings.TrimSpace(string(output)))
+
+	var size int64
+	err = filepath.WalkDir(gitDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can disappear while gc is repacking, skip them
+			return nil
+		}
+		if !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure git directory: %w", err)
+	}
+
+	return size, nil
+}
+
+// GetRepoStats returns object counts and sizes from git count-objects
+func GetRepoStats() (models.RepoStats, error) {
+	// Without -H sizes are reported as plain KiB, which is easier to parse
+	cmd := command("count-objects", "-v")
+	output, err := cmd.Output()
+	if err != nil {
+		return models.RepoStats{}, fmt.Errorf("failed to count objects: %w", err)
+	}
+	return parseCountObjects(string(output)), nil
+}
+
+func parseCountObjects(output string) models.RepoStats {
+	var stats models.RepoStats
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch key {
+		case "count":
+			stats.LooseObjects = int(n)
+		case "size":
+			stats.LooseSize = n * 1024
+		case "in-pack":
+			stats.PackedObjects = int(n)
+		case "packs":
+			stats.Packs = int(n)
+		case "size-pack":
+			stats.PackSize = n * 1024
+		case "prune-packable":
+			stats.PrunePackable = int(n)
+		case "garbage":
+			stats.Garbage = int(n)
+		case "size-garbage":
+			stats.GarbageSize = n * 1024
+		}
+	}
+	return stats
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+// Reflog returns up to limit entries of HEAD's reflog, newest first
+func Reflog(limit int) ([]models.ReflogEntry, error) {
+	// Format: hash NUL short hash NUL selector NUL subject NUL timestamp
+	args := []string{"reflog", "--format=%H%x00%h%x00%gd%x00%gs%x00%ct"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("-%d", limit))
+	}
+
+	cmd := command(args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reflog: %w", err)
+	}
+
+	return parseReflog(output), nil
+}
+
+func parseReflog(output []byte) []models.ReflogEntry {
+	var entries []models.ReflogEntry
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), "\x00")
+		if len(parts) < 5 {
+			continue
+		}
+
+		unixTime, _ := strconv.ParseInt(parts[4], 10, 64)
+
+		// Subject: "checkout: moving from main to feature/x"
+		action, message, found := strings.Cut(parts[3], ": ")
+		if !found {
+			action, message = parts[3], ""
+		}
+
+		entries = append(entries, models.ReflogEntry{
+			Hash:      parts[0],
+			ShortHash: parts[1],
+			Selector:  parts[2],
+			Action:    action,
+			Message:   message,
+			Date:      time.Unix(unixTime, 0),
+		})
+	}
+
+	return entries
+}
+
+// CreateBranchAt creates a branch pointing at commit without switching to it
+func CreateBranchAt(name, commit string) error {
+	cmd := command("branch", name, commit)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create branch: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// BranchExists reports whether a local branch exists
+func BranchExists(name string) bool {
+	cmd := command("rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return cmd.Run() == nil
+}
@@ -0,0 +1,364 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+// GetRemoteURL returns the fetch URL of the given remote
+func GetRemoteURL(remote string) (string, error) {
+	cmd := command("remote", "get-url", remote)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get url for remote %s: %w", remote, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ListRemotes returns the configured remotes with their fetch and push URLs
+func ListRemotes() ([]models.Remote, error) {
+	cmd := command("remote", "-v")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	return parseRemotes(output), nil
+}
+
+// parseRemotes parses git remote -v, which lists each remote twice:
+// origin	git@github.com:user/repo.git (fetch)
+// origin	git@github.com:user/repo.git (push)
+func parseRemotes(output []byte) []models.Remote {
+	var remotes []models.Remote
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		remoteURL, kind, _ := strings.Cut(rest, " ")
+
+		i, seen := index[name]
+		if !seen {
+			i = len(remotes)
+			index[name] = i
+			remotes = append(remotes, models.Remote{Name: name})
+		}
+		switch kind {
+		case "(fetch)":
+			remotes[i].FetchURL = remoteURL
+		case "(push)":
+			remotes[i].PushURL = remoteURL
+		}
+	}
+
+	return remotes
+}
+
+// AddRemote adds a remote named name pointing at remoteURL
+func AddRemote(name, remoteURL string) error {
+	cmd := command("remote", "add", name, remoteURL)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add remote: %s", strings.TrimSpace(string(output)))
+	}
+	invalidateDefaultRemote()
+	InvalidateDefaultBranchCache()
+	return nil
+}
+
+// RemoveRemote removes a remote along with its remote-tracking branches
+func RemoveRemote(name string) error {
+	cmd := command("remote", "remove", name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove remote: %s", strings.TrimSpace(string(output)))
+	}
+	invalidateDefaultRemote()
+	InvalidateDefaultBranchCache()
+	return nil
+}
+
+// RenameRemote renames a remote, moving its remote-tracking branches and
+// the upstreams that point at it. A renamed default remote stays the default.
+func RenameRemote(oldName, newName string) error {
+	wasDefault := configuredRemote() == oldName
+
+	cmd := command("remote", "rename", oldName, newName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to rename remote: %s", strings.TrimSpace(string(output)))
+	}
+	invalidateDefaultRemote()
+	if wasDefault {
+		if err := SetDefaultRemote(newName); err != nil {
+			return err
+		}
+	}
+	InvalidateDefaultBranchCache()
+	return nil
+}
+
+// defaultRemoteKey is the git config key holding the remote chosen in the
+// remote view
+const defaultRemoteKey = "gitgoblin.remote"
+
+// defaultRemoteCache holds the remote DefaultRemote picked, which the
+// dashboard asks for several times on every refresh
+var defaultRemoteCache struct {
+	sync.Mutex
+	valid bool
+	name  string
+}
+
+// DefaultRemote returns the remote the default branch is detected from and
+// new branches are pushed to: the one chosen with SetDefaultRemote if it
+// still exists, otherwise origin, otherwise the first remote. Without any
+// remotes it is "origin" so messages still make sense. It is picked once
+// and then cached until the remotes or the repository change.
+func DefaultRemote() string {
+	defaultRemoteCache.Lock()
+	defer defaultRemoteCache.Unlock()
+
+	if !defaultRemoteCache.valid {
+		name, err := pickDefaultRemote()
+		if err != nil {
+			// Try again next time rather than sticking with the fallback
+			return name
+		}
+		defaultRemoteCache.name, defaultRemoteCache.valid = name, true
+	}
+	return defaultRemoteCache.name
+}
+
+// invalidateDefaultRemote makes the next DefaultRemote pick the remote again
+func invalidateDefaultRemote() {
+	defaultRemoteCache.Lock()
+	defaultRemoteCache.valid = false
+	defaultRemoteCache.Unlock()
+}
+
+// pickDefaultRemote works out the remote DefaultRemote returns, falling
+// back to "origin" when the remotes can't be listed
+func pickDefaultRemote() (string, error) {
+	remotes, err := ListRemotes()
+	if err != nil {
+		return "origin", err
+	}
+	if len(remotes) == 0 {
+		return "origin", nil
+	}
+
+	has := func(name string) bool {
+		for _, r := range remotes {
+			if r.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	if chosen := configuredRemote(); chosen != "" && has(chosen) {
+		return chosen, nil
+	}
+	if has("origin") {
+		return "origin", nil
+	}
+	return remotes[0].Name, nil
+}
+
+// SetDefaultRemote makes name the remote DefaultRemote returns, stored in
+// the repository's git config
+func SetDefaultRemote(name string) error {
+	cmd := command("config", defaultRemoteKey, name)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to set default remote: %s", strings.TrimSpace(string(output)))
+	}
+	invalidateDefaultRemote()
+	InvalidateDefaultBranchCache()
+	return nil
+}
+
+// configuredRemote returns the remote chosen with SetDefaultRemote, or ""
+func configuredRemote() string {
+	output, err := command("config", "--get", defaultRemoteKey).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// parseRemoteURL splits a remote URL into its host and repository path
+// https://github.com/user/repo.git -> github.com, user/repo
+// git@github.com:user/repo.git -> github.com, user/repo
+// ssh://git@github.com:22/user/repo.git -> github.com, user/repo
+func parseRemoteURL(remoteURL string) (host, path string, ok bool) {
+	remoteURL = strings.TrimSuffix(strings.TrimSpace(remoteURL), ".git")
+
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil || u.Host == "" {
+			return "", "", false
+		}
+		host = u.Hostname()
+		path = strings.Trim(u.Path, "/")
+	} else {
+		// SCP-like syntax: [user@]host:path
+		colonIdx := strings.Index(remoteURL, ":")
+		if colonIdx == -1 {
+			return "", "", false
+		}
+		host = remoteURL[:colonIdx]
+		if atIdx := strings.LastIndex(host, "@"); atIdx != -1 {
+			host = host[atIdx+1:]
+		}
+		path = strings.Trim(remoteURL[colonIdx+1:], "/")
+	}
+
+	if host == "" || path == "" {
+		return "", "", false
+	}
+	return host, path, true
+}
+
+// GetPullRequestURL builds the web URL for opening a pull request from
+// branch into base on the default remote. GitLab and Bitbucket hosts get
+// their own URL shapes, everything else uses GitHub's compare page.
+func GetPullRequestURL(branch, base string) (string, error) {
+	remoteURL, err := GetRemoteURL(DefaultRemote())
+	if err != nil {
+		return "", err
+	}
+
+	host, path, ok := parseRemoteURL(remoteURL)
+	if !ok {
+		return "", fmt.Errorf("could not parse remote url: %s", remoteURL)
+	}
+
+	u := url.URL{Scheme: "https", Host: host}
+	switch {
+	case strings.Contains(host, "gitlab"):
+		u.Path = "/" + path + "/-/merge_requests/new"
+		q := url.Values{}
+		q.Set("merge_request[source_branch]", branch)
+		q.Set("merge_request[target_branch]", base)
+		u.RawQuery = q.Encode()
+	case strings.Contains(host, "bitbucket"):
+		u.Path = "/" + path + "/pull-requests/new"
+		q := url.Values{}
+		q.Set("source", branch)
+		q.Set("dest", base)
+		u.RawQuery = q.Encode()
+	default:
+		u.Path = fmt.Sprintf("/%s/compare/%s...%s", path, base, branch)
+		u.RawQuery = "expand=1"
+	}
+
+	return u.String(), nil
+}
+
+// remoteCommand builds a git command that talks to a remote. Terminal
+// prompts are disabled so a missing credential fails instead of hanging
+// behind the TUI: GIT_TERMINAL_PROMPT for HTTPS, and ssh's BatchMode for an
+// SSH key passphrase, unless ssh is configured some other way already.
+// Keys loaded in an ssh agent still work.
+func remoteCommand(args ...string) *exec.Cmd {
+	cmd := command(args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if os.Getenv("GIT_SSH_COMMAND") == "" && os.Getenv("GIT_SSH") == "" && !hasSSHCommand() {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	return cmd
+}
+
+// hasSSHCommand reports whether core.sshCommand sets how git runs ssh
+func hasSSHCommand() bool {
+	return command("config", "--get", "core.sshCommand").Run() == nil
+}
+
+// Fetch downloads objects and refs from the given remote
+func Fetch(remote string) error {
+	cmd := remoteCommand("fetch", "--quiet", remote)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("fetch failed: %s", strings.TrimSpace(string(output)))
+	}
+	// Fetching can create or move <remote>/HEAD
+	InvalidateDefaultBranchCache()
+	return nil
+}
+
+// Pull fast-forwards the current branch to branch on the given remote.
+// Diverged branches fail rather than opening an editor for a merge commit.
+func Pull(remote, branch string) error {
+	cmd := remoteCommand("pull", "--ff-only", "--quiet", remote, branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("pull failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Push pushes branch to remoteBranch on the given remote, or to a branch of
+// the same name when remoteBranch is empty. Force uses --force-with-lease
+// so commits pushed by someone else are not overwritten.
+func Push(remote, branch, remoteBranch string, force bool) error {
+	args := []string{"push", "--quiet"}
+	if force {
+		args = append(args, "--force-with-lease")
+	}
+	// Push to the tracked branch even when it is named differently
+	refspec := branch
+	if remoteBranch != "" && remoteBranch != branch {
+		refspec = branch + ":" + remoteBranch
+	}
+	args = append(args, remote, refspec)
+
+	cmd := remoteCommand(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("push failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// PushSetUpstream pushes branch to the given remote and tracks it as upstream
+func PushSetUpstream(remote, branch string) error {
+	cmd := remoteCommand("push", "--quiet", "--set-upstream", remote, branch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("push failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetUpstream returns the remote and remote branch name that branch tracks,
+// or empty strings if it has no upstream
+func GetUpstream(branch string) (remote, remoteBranch string, err error) {
+	cmd := command("config", "--get", "branch."+branch+".remote")
+	output, err := cmd.Output()
+	if err != nil {
+		// git config exits 1 when the key is unset
+		return "", "", nil
+	}
+	remote = strings.TrimSpace(string(output))
+
+	cmd = command("config", "--get", "branch."+branch+".merge")
+	output, err = cmd.Output()
+	if err != nil {
+		return "", "", nil
+	}
+	remoteBranch = strings.TrimPrefix(strings.TrimSpace(string(output)), "refs/heads/")
+
+	return remote, remoteBranch, nil
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo is the repository every git command runs in. Commands run from the
+// top of its working tree, so paths are always relative to Root no matter
+// which directory GitGoblin was started from.
+type Repo struct {
+	Root   string // top of the working tree
+	GitDir string // git directory, outside Root for worktrees and submodules where .git is a file
+}
+
+// current is the repository set by Use. Its zero value runs commands in
+// the process working directory.
+var current = &Repo{}
+
+// Open resolves the repository containing path. git follows a .git file to
+// the real git directory, so linked worktrees and submodules work as well
+// as plain clones. Bare repositories have no working tree and are refused.
+func Open(path string) (*Repo, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--is-bare-repository", "--absolute-git-dir")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git repository", path)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("failed to locate git directory: %s", string(output))
+	}
+	if lines[0] == "true" {
+		return nil, fmt.Errorf("%s is a bare repository, open one of its worktrees instead", path)
+	}
+
+	cmd = exec.Command("git", "-C", path, "rev-parse", "--show-toplevel")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		// e.g. started from inside the .git directory
+		return nil, fmt.Errorf("%s is not inside a working tree", path)
+	}
+
+	return &Repo{
+		Root:   strings.TrimSpace(string(output)),
+		GitDir: lines[1],
+	}, nil
+}
+
+// Use makes repo the repository git commands run in
+func Use(repo *Repo) {
+	current = repo
+	invalidateDefaultRemote()
+	InvalidateDefaultBranchCache()
+}
+
+// Current returns the repository git commands run in
+func Current() *Repo {
+	return current
+}
+
+// command builds a git command that runs at the top of the current
+// repository
+func command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = current.Root
+	return cmd
+}
+
+// repoPath resolves a path git printed relative to the repository root
+func repoPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(current.Root, path)
+}
@@ -0,0 +1,278 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+// StashPush stashes the working tree, including untracked files when requested
+func StashPush(message string, includeUntracked bool) error {
+	args := []string{"stash", "push"}
+	if includeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+
+	cmd := command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to stash: %s", string(output))
+	}
+	return nil
+}
+
+// StashList returns all stashes, newest first
+func StashList() ([]models.Stash, error) {
+	// Format: selector NUL subject NUL timestamp
+	cmd := command("stash", "list", "--pretty=format:%gd%x00%gs%x00%ct")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+
+	return parseStashList(output), nil
+}
+
+func parseStashList(output []byte) []models.Stash {
+	var stashes []models.Stash
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), "\x00")
+		if len(parts) < 3 {
+			continue
+		}
+
+		// Selector: stash@{2}
+		selector := strings.TrimSuffix(strings.TrimPrefix(parts[0], "stash@{"), "}")
+		index, err := strconv.Atoi(selector)
+		if err != nil {
+			continue
+		}
+
+		unixTime, _ := strconv.ParseInt(parts[2], 10, 64)
+
+		// Subject: "WIP on main: abc1234 last commit" or "On main: message"
+		subject := parts[1]
+		subject = strings.TrimPrefix(subject, "WIP on ")
+		subject = strings.TrimPrefix(subject, "On ")
+		branch, message := "", subject
+		if idx := strings.Index(subject, ": "); idx != -1 {
+			branch = subject[:idx]
+			message = subject[idx+2:]
+		}
+
+		stashes = append(stashes, models.Stash{
+			Index:   index,
+			Branch:  branch,
+			Message: message,
+			Date:    time.Unix(unixTime, 0),
+		})
+	}
+
+	return stashes
+}
+
+// ErrStashPopConflict is returned when a stash only partially applies because
+// it conflicts with the working tree. Git keeps the stash in that case, and
+// AbortStashPop can undo the partial application.
+var ErrStashPopConflict = errors.New("stashed changes conflict with the working tree")
+
+// StashPop applies a stash and removes it from the stash list
+func StashPop(index int) error {
+	return runStash("pop", index)
+}
+
+// StashApply applies a stash, keeping it in the stash list
+func StashApply(index int) error {
+	return runStash("apply", index)
+}
+
+// StashDrop removes a stash without applying it
+func StashDrop(index int) error {
+	return runStash("drop", index)
+}
+
+// StashShow returns the patch a stash would apply
+func StashShow(index int) (string, error) {
+	cmd := command("stash", "show", "-p", stashRef(index))
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to show stash: %w", err)
+	}
+	return string(output), nil
+}
+
+func runStash(action string, index int) error {
+	cmd := command("stash", action, stashRef(index))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if action != "drop" && hasUnmergedFiles() {
+			return fmt.Errorf("%w, %s was kept", ErrStashPopConflict, stashRef(index))
+		}
+		return fmt.Errorf("failed to %s stash: %s", action, string(output))
+	}
+	return nil
+}
+
+// AbortStashPop undoes a stash pop or apply that stopped on conflicts by
+// restoring every path the stash touches to HEAD. Git refuses to apply over
+// local changes to those paths, so any other changes are left alone, and
+// since git keeps a conflicting stash it is still there to retry later.
+func AbortStashPop(index int) error {
+	ref := stashRef(index)
+
+	changed, err := nulPaths("diff", "--name-only", "--no-renames", "-z", ref+"^1", ref)
+	if err != nil {
+		return fmt.Errorf("failed to read stash: %w", err)
+	}
+	if len(changed) > 0 {
+		// Only pass paths git knows about, restore rejects the rest
+		args := append([]string{"ls-files", "-z", "--"}, changed...)
+		known, err := nulPaths(args...)
+		if err != nil {
+			return fmt.Errorf("failed to read index: %w", err)
+		}
+		args = append([]string{"ls-tree", "-r", "--name-only", "-z", "HEAD", "--"}, changed...)
+		inHead, err := nulPaths(args...)
+		if err != nil {
+			return fmt.Errorf("failed to read HEAD: %w", err)
+		}
+		if err := restorePaths(append(known, inHead...)); err != nil {
+			return err
+		}
+	}
+
+	// Untracked files stashed with --include-untracked live in the third parent
+	if command("rev-parse", "-q", "--verify", ref+"^3").Run() == nil {
+		untracked, err := nulPaths("ls-tree", "-r", "--name-only", "-z", ref+"^3")
+		if err != nil {
+			return fmt.Errorf("failed to read stash: %w", err)
+		}
+		if len(untracked) > 0 {
+			args := append([]string{"--literal-pathspecs", "clean", "-f", "-q", "--"}, untracked...)
+			cmd := command(args...)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to remove stashed untracked files: %s", string(output))
+			}
+		}
+	}
+	return nil
+}
+
+// restorePaths resets both the index and working tree of paths to HEAD
+func restorePaths(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	cmd := command("--literal-pathspecs", "restore", "--source=HEAD", "--staged", "--worktree",
+		"--pathspec-from-file=-", "--pathspec-file-nul")
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00"))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to restore files: %s", string(output))
+	}
+	return nil
+}
+
+// hasUnmergedFiles reports whether the index has conflicted entries
+func hasUnmergedFiles() bool {
+	files, err := ConflictedFiles()
+	return err == nil && len(files) > 0
+}
+
+// ConflictedFiles lists the paths with unresolved conflicts
+func ConflictedFiles() ([]string, error) {
+	return nulPaths("diff", "--name-only", "--diff-filter=U", "-z")
+}
+
+// nulPaths runs a git command with --literal-pathspecs and splits its
+// NUL-separated output
+func nulPaths(args ...string) ([]string, error) {
+	cmd := command(append([]string{"--literal-pathspecs"}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// MoveChangesToBranch carries uncommitted changes, untracked files included,
+// over to another branch by stashing, switching and popping. If the switch
+// fails the changes are restored on the current branch.
+func MoveChangesToBranch(branch string) error {
+	if err := stashAndSwitch(branch, "GitGoblin: moving changes to "+branch); err != nil {
+		return err
+	}
+
+	if err := StashPop(0); err != nil {
+		if errors.Is(err, ErrStashPopConflict) {
+			return fmt.Errorf("switched to %s but %w", branch, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// StashAndSwitchBranch stashes uncommitted changes, untracked files
+// included, and switches to branch, leaving the changes in stash@{0}. If
+// the switch fails the changes are restored on the current branch.
+func StashAndSwitchBranch(branch string) error {
+	return stashAndSwitch(branch, "GitGoblin: switching to "+branch)
+}
+
+func stashAndSwitch(branch, message string) error {
+	dirty, err := HasUncommittedChanges()
+	if err != nil {
+		return err
+	}
+	if !dirty {
+		return fmt.Errorf("no changes to stash")
+	}
+
+	before := stashTop()
+	if err := StashPush(message, true); err != nil {
+		return err
+	}
+	// Guard against popping an older stash if nothing was actually stashed
+	if stashTop() == before {
+		return fmt.Errorf("failed to stash: nothing was stashed")
+	}
+
+	if err := SwitchBranch(branch); err != nil {
+		if popErr := StashPop(0); popErr != nil {
+			return fmt.Errorf("%v; your changes are kept in %s", err, stashRef(0))
+		}
+		return err
+	}
+	return nil
+}
+
+// stashTop returns the hash of the newest stash, or "" if there is none
+func stashTop() string {
+	cmd := command("rev-parse", "-q", "--verify", "refs/stash")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func stashRef(index int) string {
+	return fmt.Sprintf("stash@{%d}", index)
+}
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+// GetRepoState detects in-progress operations and whether HEAD is detached
+func GetRepoState() (models.RepoState, error) {
+	state := models.RepoState{}
+
+	cmd := command("rev-parse", "--absolute-git-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return state, fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	gitDir := strings.TrimSpace(string(output))
+
+	switch {
+	case isDir(filepath.Join(gitDir, "rebase-merge")):
+		// Interactive and merge-backend rebases
+		dir := filepath.Join(gitDir, "rebase-merge")
+		state.Operation = models.OperationRebase
+		state.Step = readInt(filepath.Join(dir, "msgnum"))
+		state.Total = readInt(filepath.Join(dir, "end"))
+		state.RebaseBranch = readRef(filepath.Join(dir, "head-name"))
+	case isDir(filepath.Join(gitDir, "rebase-apply")):
+		// git am and apply-backend rebases
+		dir := filepath.Join(gitDir, "rebase-apply")
+		state.Operation = models.OperationRebase
+		state.Step = readInt(filepath.Join(dir, "next"))
+		state.Total = readInt(filepath.Join(dir, "last"))
+		state.RebaseBranch = readRef(filepath.Join(dir, "head-name"))
+	case fileExists(filepath.Join(gitDir, "MERGE_HEAD")):
+		state.Operation = models.OperationMerge
+	case fileExists(filepath.Join(gitDir, "CHERRY_PICK_HEAD")):
+		state.Operation = models.OperationCherryPick
+	case fileExists(filepath.Join(gitDir, "REVERT_HEAD")):
+		state.Operation = models.OperationRevert
+	}
+
+	// symbolic-ref fails when HEAD points directly at a commit
+	cmd = command("symbolic-ref", "-q", "HEAD")
+	if err := cmd.Run(); err != nil {
+		state.Detached = true
+		cmd = command("rev-parse", "--short", "HEAD")
+		if output, err := cmd.Output(); err == nil {
+			state.HeadShort = strings.TrimSpace(string(output))
+		}
+	}
+
+	return state, nil
+}
+
+// IsEmptyRepo reports whether the repository has no commits yet, as right
+// after git init, where HEAD names a branch that doesn't exist
+func IsEmptyRepo() (bool, error) {
+	cmd := command("rev-parse", "-q", "--verify", "HEAD")
+	if err := cmd.Run(); err != nil {
+		// With -q an unresolvable HEAD exits 1 without a message
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return false, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readInt reads a file holding a single number, returning 0 if unreadable
+func readInt(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return n
+}
+
+// readRef reads a file holding a ref name and strips the refs/heads/ prefix
+func readRef(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(data)), "refs/heads/")
+}
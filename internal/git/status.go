@@ -4,23 +4,86 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
 
 // GetWorkingTreeStatus returns all file changes in the working tree
 func GetWorkingTreeStatus() ([]models.FileChange, error) {
-	cmd := exec.Command("git", "status", "--porcelain=v1")
-	output, err := cmd.Output()
+	return GetWorkingTreeStatusWith(StatusOptions{})
+}
+
+// StatusOptions controls what GetWorkingTreeStatusWith lists
+type StatusOptions struct {
+	Ignored bool     // include ignored files and directories
+	Expand  []string // collapsed directories, e.g. "build/", to list file by file
+}
+
+// GetWorkingTreeStatusWith returns all file changes in the working tree.
+// Untracked and ignored directories are listed as a single "dir/" entry
+// unless they are in opts.Expand, in which case the files inside replace it.
+func GetWorkingTreeStatusWith(opts StatusOptions) ([]models.FileChange, error) {
+	args := []string{"status", "--porcelain=v1"}
+	if opts.Ignored {
+		args = append(args, "--ignored")
+	}
+	output, err := command(args...).Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
-	return parseStatus(output)
+	files, err := parseStatus(output)
+	if err != nil || len(opts.Expand) == 0 {
+		return files, err
+	}
+
+	expand := make(map[string]bool)
+	for _, dir := range opts.Expand {
+		expand[dir] = true
+	}
+
+	var expanded []models.FileChange
+	for _, file := range files {
+		if !file.IsDir() || !expand[file.Path] {
+			expanded = append(expanded, file)
+			continue
+		}
+		contents, err := dirStatus(file.Path, opts.Ignored)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, contents...)
+	}
+	return expanded, nil
+}
+
+// dirStatus lists the untracked or ignored files inside dir, one entry each
+func dirStatus(dir string, ignored bool) ([]models.FileChange, error) {
+	args := []string{"--literal-pathspecs", "status", "--porcelain=v1", "--untracked-files=all"}
+	if ignored {
+		args = append(args, "--ignored")
+	}
+	output, err := command(append(args, "--", dir)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	files, err := parseStatus(output)
+	if err != nil {
+		return nil, err
+	}
+	// Tracked changes under dir are already in the top-level listing
+	var contents []models.FileChange
+	for _, file := range files {
+		if file.IsUntracked || file.IsIgnored {
+			contents = append(contents, file)
+		}
+	}
+	return contents, nil
 }
 
 // parseStatus parses git status --porcelain output
@@ -38,18 +101,28 @@ func parseStatus(output []byte) ([]models.FileChange, error) {
 
 		stagedChar := string(line[0])
 		workingChar := string(line[1])
-		path := strings.TrimSpace(line[3:])
+		path := line[3:]
+		oldPath := ""
 
-		// Handle renames (format: "R  old -> new")
-		if stagedChar == "R" {
-			parts := strings.Split(path, " -> ")
-			if len(parts) == 2 {
-				path = parts[1] // Use new name
-			}
+		// Handle renames and copies (format: "R  old -> new"), staged or
+		// in the working tree (" R" after git add -N)
+		if strings.ContainsAny(stagedChar+workingChar, "RC") {
+			oldPath, path = splitRenamePaths(path)
 		}
 
 		file := models.FileChange{
-			Path: path,
+			Path:    unquotePath(path),
+			OldPath: unquotePath(oldPath),
+		}
+
+		// Conflicts use their own XY pairs, e.g. "AA" is added by both
+		// sides rather than a staged addition
+		if models.IsUnmerged(stagedChar + workingChar) {
+			file.StagedStatus = models.FileStatus(stagedChar)
+			file.Status = models.FileStatus(workingChar)
+			file.IsConflicted = true
+			files = append(files, file)
+			continue
 		}
 
 		// Parse staged status
@@ -77,9 +150,14 @@ func parseStatus(output []byte) ([]models.FileChange, error) {
 			file.Status = models.StatusModified
 		case "D":
 			file.Status = models.StatusDeleted
+		case "R":
+			file.Status = models.StatusRenamed
 		case "?":
 			file.Status = models.StatusUntracked
 			file.IsUntracked = true
+		case "!":
+			file.Status = models.StatusIgnored
+			file.IsIgnored = true
 		}
 
 		files = append(files, file)
@@ -88,24 +166,134 @@ func parseStatus(output []byte) ([]models.FileChange, error) {
 	return files, nil
 }
 
+// splitRenamePaths splits porcelain's "old -> new" into both paths. Either
+// side may be quoted, in which case it can contain " -> " itself.
+func splitRenamePaths(s string) (oldPath, newPath string) {
+	if strings.HasPrefix(s, "\"") {
+		// Find the closing quote, skipping escaped characters
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				if rest, ok := strings.CutPrefix(s[i+1:], " -> "); ok {
+					return s[:i+1], rest
+				}
+				break
+			}
+		}
+		return "", s
+	}
+
+	if idx := strings.Index(s, " -> "); idx != -1 {
+		return s[:idx], s[idx+4:]
+	}
+	return "", s
+}
+
+// unquotePath undoes git's C-style quoting of paths with spaces, control
+// or non-ASCII characters, e.g. "\303\251.txt" -> é.txt
+func unquotePath(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
 // StageFile stages a specific file
 func StageFile(path string) error {
-	cmd := exec.Command("git", "add", path)
+	cmd := command("add", path)
 	return cmd.Run()
 }
 
+// MarkResolved stages a conflicted file as resolved, including one that
+// was resolved by deleting it
+func MarkResolved(path string) error {
+	cmd := command("--literal-pathspecs", "add", "-A", "--", path)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to mark %s resolved: %s", path, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// HasConflictMarkers reports whether a file still contains conflict
+// markers. A file that doesn't exist has none.
+func HasConflictMarkers(path string) bool {
+	data, err := os.ReadFile(repoPath(path))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return true
+		}
+	}
+	return false
+}
+
 // UnstageFile unstages a specific file
 func UnstageFile(path string) error {
-	cmd := exec.Command("git", "restore", "--staged", path)
+	cmd := command("restore", "--staged", path)
 	return cmd.Run()
 }
 
 // StageAll stages all changes
 func StageAll() error {
-	cmd := exec.Command("git", "add", "-A")
+	cmd := command("add", "-A")
 	return cmd.Run()
 }
 
+// StageFiles stages the changes to the given paths, including deletions
+func StageFiles(paths []string) error {
+	args := append([]string{"--literal-pathspecs", "add", "-A", "--"}, paths...)
+	cmd := command(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to stage files: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DiscardFile throws away the working-tree changes to a file, restoring it
+// from the index. Untracked files are deleted from disk.
+func DiscardFile(path string, untracked bool) error {
+	var cmd *exec.Cmd
+	if untracked {
+		cmd = command("--literal-pathspecs", "clean", "-f", "-d", "-q", "--", path)
+	} else {
+		cmd = command("--literal-pathspecs", "restore", "--", path)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to discard %s: %s", path, string(output))
+	}
+	return nil
+}
+
+// DiscardAll throws away every working-tree change and deletes untracked
+// files, leaving staged changes and ignored files alone
+func DiscardAll() error {
+	// restore fails on a repo without tracked files, so only run it when
+	// the working tree differs from the index
+	if err := command("diff", "--quiet").Run(); err != nil {
+		output, err := command("restore", "--", ":/").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to discard changes: %s", string(output))
+		}
+	}
+
+	output, err := command("clean", "-f", "-d", "-q", "--", ":/").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete untracked files: %s", string(output))
+	}
+	return nil
+}
+
 // GetDiff returns the diff for a file
 func GetDiff(path string, staged bool) (string, error) {
 	args := []string{"diff"}
@@ -114,7 +302,7 @@ func GetDiff(path string, staged bool) (string, error) {
 	}
 	args = append(args, "--", path)
 
-	cmd := exec.Command("git", args...)
+	cmd := command(args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get diff: %w", err)
@@ -123,14 +311,21 @@ func GetDiff(path string, staged bool) (string, error) {
 	return string(output), nil
 }
 
-// Commit creates a commit with the given message
-func Commit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	output, err := cmd.CombinedOutput()
+// GetDiffAgainstCommit returns the diff from a commit to the staged version
+// of a file
+func GetDiffAgainstCommit(path, commit string) (string, error) {
+	cmd := command("diff", "--cached", commit, "--", path)
+	output, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("commit failed: %s", string(output))
+		return "", fmt.Errorf("failed to get diff against %s: %w", commit, err)
 	}
-	return nil
+
+	return string(output), nil
+}
+
+// Commit creates a commit with the given message
+func Commit(message string, opts CommitOptions) error {
+	return runCommit("commit", messageArgs(message), opts)
 }
 
 // HasUncommittedChanges checks if there are any uncommitted changes
@@ -142,31 +337,10 @@ func HasUncommittedChanges() (bool, error) {
 	return len(files) > 0, nil
 }
 
-// GetLastCommitTime returns the timestamp of the last commit
-func GetLastCommitTime() (time.Time, error) {
-	cmd := exec.Command("git", "log", "-1", "--format=%ct")
-	output, err := cmd.Output()
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to get last commit time: %w", err)
-	}
-
-	timestampStr := strings.TrimSpace(string(output))
-	if timestampStr == "" {
-		return time.Time{}, fmt.Errorf("no commits found")
-	}
-
-	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse timestamp: %w", err)
-	}
-
-	return time.Unix(timestamp, 0), nil
-}
-
 // GetLineStats returns per-file line statistics for uncommitted changes
 // Returns a map of filename -> [added, deleted]
 func GetLineStats() (map[string][2]int, error) {
-	cmd := exec.Command("git", "diff", "--numstat")
+	cmd := command("diff", "--numstat")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get line stats: %w", err)
@@ -205,5 +379,66 @@ func GetLineStats() (map[string][2]int, error) {
 		fileStats[filename] = [2]int{added, deleted}
 	}
 
+	// git diff ignores untracked files, count their lines as additions
+	untracked, err := getUntrackedFiles()
+	if err != nil {
+		return fileStats, nil
+	}
+	for _, path := range untracked {
+		if lines, ok := countFileLines(repoPath(path)); ok {
+			fileStats[path] = [2]int{lines, 0}
+		}
+	}
+
 	return fileStats, nil
 }
+
+// maxUntrackedStatSize is the largest untracked file whose lines are counted
+const maxUntrackedStatSize = 8 << 20
+
+// getUntrackedFiles lists untracked files that aren't ignored, expanding
+// untracked directories into the files they contain
+func getUntrackedFiles() ([]string, error) {
+	cmd := command("ls-files", "--others", "--exclude-standard", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var files []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// countFileLines counts the lines in a file the way numstat would for a new
+// file. Binary and very large files are skipped.
+func countFileLines(path string) (int, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxUntrackedStatSize {
+		return 0, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	// Same heuristic as git: a NUL byte in the first 8000 bytes means binary
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	if bytes.IndexByte(head, 0) != -1 {
+		return 0, false
+	}
+
+	lines := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines, true
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Version is a git release, e.g. 2.39.5
+type Version struct {
+	Major, Minor, Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is min or newer
+func (v Version) AtLeast(min Version) bool {
+	if v.Major != min.Major {
+		return v.Major > min.Major
+	}
+	if v.Minor != min.Minor {
+		return v.Minor > min.Minor
+	}
+	return v.Patch >= min.Patch
+}
+
+// MinVersion is the oldest git GitGoblin works with. 2.25 brought restore's
+// --pathspec-from-file, and switch, restore and branch --show-current are
+// all a little older.
+var MinVersion = Version{Major: 2, Minor: 25}
+
+// ErrGitNotFound is returned by CheckVersion when there is no git on PATH
+var ErrGitNotFound = errors.New("git was not found on your PATH, install it from https://git-scm.com/downloads and try again")
+
+// CheckVersion makes sure git is installed and at least MinVersion,
+// returning the version found
+func CheckVersion() (Version, error) {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return Version{}, ErrGitNotFound
+	}
+
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to run %s --version: %w", path, err)
+	}
+	version, ok := parseVersion(string(output))
+	if !ok {
+		return Version{}, fmt.Errorf("failed to read git version from %q", strings.TrimSpace(string(output)))
+	}
+	if !version.AtLeast(MinVersion) {
+		return version, fmt.Errorf("git %s is too old, GitGoblin needs %d.%d or newer", version, MinVersion.Major, MinVersion.Minor)
+	}
+	return version, nil
+}
+
+// parseVersion reads git --version output, which vendors decorate, e.g.
+// "git version 2.39.3 (Apple Git-146)" or "git version 2.44.0.windows.1"
+func parseVersion(output string) (Version, bool) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return Version{}, false
+	}
+
+	parts := strings.Split(fields[2], ".")
+	if len(parts) < 2 {
+		return Version{}, false
+	}
+	var numbers [3]int
+	for i := 0; i < len(parts) && i < 3; i++ {
+		// Only the leading digits count, e.g. the 0 of "0-rc1"
+		digits := strings.IndexFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+		if digits == -1 {
+			digits = len(parts[i])
+		}
+		n, err := strconv.Atoi(parts[i][:digits])
+		if err != nil {
+			if i < 2 {
+				return Version{}, false
+			}
+			break
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, true
+}
@@ -1,10 +1,16 @@
 package models
 
+import "time"
+
 type Branch struct {
 	Name       string
 	Hash       string
 	IsCurrent  bool
 	IsRemote   bool
-	Upstream   string // e.g., "origin/main: ahead 2"
-	LastCommit string
+	Upstream   string    // e.g. "origin/main", empty without one
+	Ahead      int       // commits not yet on the upstream
+	Behind     int       // upstream commits not yet merged
+	Gone       bool      // upstream is configured but no longer exists
+	LastCommit string    // subject of the last commit
+	Date       time.Time // committer date of the last commit
 }
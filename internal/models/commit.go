@@ -3,12 +3,47 @@ package models
 import "time"
 
 type Commit struct {
-	Hash      string
-	ShortHash string
-	Author    string
-	Email     string
-	Date      time.Time
-	Message   string
-	Refs      []string // branch names, tags
-	Parents   []string
+	Hash           string    `json:"hash"`
+	ShortHash      string    `json:"short_hash"`
+	Author         string    `json:"author"`
+	Email          string    `json:"email"`
+	Date           time.Time `json:"date"` // author date
+	Committer      string    `json:"committer"`
+	CommitterEmail string    `json:"committer_email"`
+	CommitDate     time.Time `json:"commit_date"`
+	Message        string    `json:"message"`
+	Refs           []string  `json:"refs"` // branch names, tags
+	Parents        []string  `json:"parents"`
+}
+
+// CommitterDiffers reports whether someone other than the author committed
+// this commit, as happens after a rebase, amend or cherry-pick by another person
+func (c Commit) CommitterDiffers() bool {
+	if c.Committer == "" {
+		return false
+	}
+	return c.Committer != c.Author || c.CommitterEmail != c.Email
+}
+
+// Trailer is a "Key: value" line in a commit message's trailer block,
+// e.g. "Reviewed-by: Jane Doe <jane@example.com>"
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// CommitDetail is a commit with its full message, changed files and diff
+type CommitDetail struct {
+	Commit
+	Body  string // full commit message, subject included
+	Files []FileStat
+	Diff  string
+}
+
+// FileStat is the number of lines a commit added and removed in one file
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
 }
@@ -0,0 +1,12 @@
+package models
+
+// Hunk is one "@@" section of a file diff
+type Hunk struct {
+	FileHeader string   // diff --git/index/---/+++ lines preceding the hunks
+	Header     string   // the "@@ -a,b +c,d @@" line
+	OldStart   int
+	OldLines   int
+	NewStart   int
+	NewLines   int
+	Lines      []string // body lines with their +/-/space prefix, CRs preserved
+}
@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 type FileStatus string
 
 const (
@@ -10,20 +12,38 @@ const (
 	StatusCopied    FileStatus = "C"  // Copied
 	StatusUntracked FileStatus = "??" // Untracked
 	StatusUpdated   FileStatus = "U"  // Updated but unmerged
+	StatusIgnored   FileStatus = "!!" // Ignored
 )
 
+// unmergedStates are the porcelain XY codes of a conflicted path, e.g. "UU"
+// for both modified or "DU" for deleted by us
+var unmergedStates = map[string]bool{
+	"DD": true, "AU": true, "UD": true, "UA": true, "DU": true, "AA": true, "UU": true,
+}
+
+// IsUnmerged reports whether a porcelain XY code marks a conflicted path
+func IsUnmerged(xy string) bool {
+	return unmergedStates[xy]
+}
+
 type FileChange struct {
-	Path          string
-	Status        FileStatus     // Working tree status
-	StagedStatus  FileStatus     // Staging area status
-	IsStaged      bool
-	IsUntracked   bool
+	Path         string     `json:"path"`
+	OldPath      string     `json:"old_path,omitempty"` // Previous path for renames and copies
+	Status       FileStatus `json:"status"`             // Working tree status
+	StagedStatus FileStatus `json:"staged_status"`      // Staging area status
+	IsStaged     bool       `json:"staged"`
+	IsUntracked  bool       `json:"untracked"`
+	IsConflicted bool       `json:"conflicted"` // Unmerged, both statuses then describe the conflict
+	IsIgnored    bool       `json:"ignored"`
 }
 
 func (f *FileChange) DisplayStatus() string {
 	if f.IsUntracked {
 		return "??"
 	}
+	if f.IsIgnored {
+		return "!!"
+	}
 
 	staged := " "
 	working := " "
@@ -37,3 +57,60 @@ func (f *FileChange) DisplayStatus() string {
 
 	return staged + working
 }
+
+// IsPartiallyStaged reports whether the file has changes both in the index
+// and in the working tree, so its staged and unstaged diffs differ
+func (f *FileChange) IsPartiallyStaged() bool {
+	return f.IsStaged && f.Status != "" && !f.IsUntracked && !f.IsConflicted
+}
+
+// IsDir reports whether the entry is a collapsed untracked or ignored
+// directory, which porcelain status lists as "dir/"
+func (f *FileChange) IsDir() bool {
+	return strings.HasSuffix(f.Path, "/")
+}
+
+// FileGroup is one section of a grouped file list, e.g. the staged files
+type FileGroup struct {
+	Title string
+	Files []FileChange
+}
+
+// fileGroupTitles lists the sections in the order they are shown
+var fileGroupTitles = []string{"Conflicted", "Staged", "Modified", "Deleted", "Untracked", "Ignored"}
+
+// group returns the title of the section the file is listed under. A
+// partially staged file is listed once, as staged.
+func (f *FileChange) group() string {
+	switch {
+	case f.IsConflicted:
+		return "Conflicted"
+	case f.IsStaged:
+		return "Staged"
+	case f.IsIgnored:
+		return "Ignored"
+	case f.IsUntracked:
+		return "Untracked"
+	case f.Status == StatusDeleted:
+		return "Deleted"
+	}
+	return "Modified"
+}
+
+// GroupFiles sorts files into sections by status, keeping their order
+// within each section and leaving out empty sections
+func GroupFiles(files []FileChange) []FileGroup {
+	byTitle := make(map[string][]FileChange)
+	for _, file := range files {
+		title := file.group()
+		byTitle[title] = append(byTitle[title], file)
+	}
+
+	var groups []FileGroup
+	for _, title := range fileGroupTitles {
+		if len(byTitle[title]) > 0 {
+			groups = append(groups, FileGroup{Title: title, Files: byTitle[title]})
+		}
+	}
+	return groups
+}
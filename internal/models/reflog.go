@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ReflogEntry is one movement of HEAD recorded in the reflog
+type ReflogEntry struct {
+	Hash      string
+	ShortHash string
+	Selector  string // e.g. "HEAD@{2}"
+	Action    string // e.g. "checkout", "reset", "commit (amend)"
+	Message   string // e.g. "moving from main to feature/x"
+	Date      time.Time
+}
@@ -0,0 +1,8 @@
+package models
+
+// Remote is a configured remote repository
+type Remote struct {
+	Name     string
+	FetchURL string
+	PushURL  string // usually the same as FetchURL
+}
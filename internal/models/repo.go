@@ -0,0 +1,71 @@
+package models
+
+// Operation is a multi-step git operation that can be left in progress
+type Operation int
+
+const (
+	OperationNone Operation = iota
+	OperationMerge
+	OperationRebase
+	OperationCherryPick
+	OperationRevert
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OperationMerge:
+		return "merge"
+	case OperationRebase:
+		return "rebase"
+	case OperationCherryPick:
+		return "cherry-pick"
+	case OperationRevert:
+		return "revert"
+	}
+	return ""
+}
+
+// RepoState describes where HEAD is and whether an operation is in progress
+type RepoState struct {
+	Operation    Operation
+	Step         int    // current rebase step, 0 if unknown
+	Total        int    // total rebase steps, 0 if unknown
+	RebaseBranch string // branch being rebased, e.g. "feature/x"
+	Detached     bool
+	HeadShort    string // abbreviated HEAD hash
+}
+
+// InProgress reports whether a merge, rebase, cherry-pick or revert is underway
+func (s RepoState) InProgress() bool {
+	return s.Operation != OperationNone
+}
+
+// RepoStats summarizes the object database as reported by git count-objects
+type RepoStats struct {
+	LooseObjects  int
+	LooseSize     int64 // bytes used by loose objects
+	PackedObjects int
+	Packs         int
+	PackSize      int64 // bytes used by packfiles
+	PrunePackable int   // loose objects that are also in a pack
+	Garbage       int
+	GarbageSize   int64 // bytes used by garbage files
+}
+
+// TotalObjects returns the number of loose and packed objects
+func (s RepoStats) TotalObjects() int {
+	return s.LooseObjects + s.PackedObjects
+}
+
+// TotalSize returns the bytes used by loose objects, packs and garbage
+func (s RepoStats) TotalSize() int64 {
+	return s.LooseSize + s.PackSize + s.GarbageSize
+}
+
+// HistoryStats counts what the repository's history is made of
+type HistoryStats struct {
+	Commits      int // reachable from HEAD
+	Branches     int // local branches
+	Tags         int
+	Contributors int // distinct author names in HEAD's history
+}
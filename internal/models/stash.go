@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Stash struct {
+	Index   int    // position in the stash list, as in stash@{Index}
+	Branch  string // branch the stash was created on
+	Message string
+	Date    time.Time
+}
@@ -0,0 +1,214 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+// aboutLoadedMsg carries what the about view shows about git and the
+// repository
+type aboutLoadedMsg struct {
+	gitVersion    string
+	root          string
+	gitDir        string
+	branch        string
+	detached      bool
+	head          *models.Commit // nil before the first commit
+	remotes       []models.Remote
+	defaultRemote string
+}
+
+type aboutCloseMsg struct{}
+
+// AboutView shows the GitGoblin and git versions and which repository,
+// branch and remotes GitGoblin is operating on, e.g. for bug reports
+type AboutView struct {
+	theme   *Theme
+	version string // GitGoblin's own
+	info    *aboutLoadedMsg
+	loader  loader
+	status  string
+	err     error
+	width   int
+	height  int
+}
+
+func NewAboutView(version string, theme *Theme) *AboutView {
+	return &AboutView{
+		theme:   theme,
+		version: version,
+		loader:  newLoader(theme),
+	}
+}
+
+func (a *AboutView) Init() tea.Cmd {
+	return a.loader.start(loadAbout)
+}
+
+func loadAbout() tea.Msg {
+	repo := git.Current()
+	msg := aboutLoadedMsg{
+		root:          repo.Root,
+		gitDir:        repo.GitDir,
+		defaultRemote: git.DefaultRemote(),
+	}
+
+	// An outdated git still reports its version
+	version, _ := git.CheckVersion()
+	msg.gitVersion = version.String()
+
+	branch, detached, err := git.GetHead()
+	if err != nil {
+		return errMsg{err}
+	}
+	msg.branch, msg.detached = branch, detached
+
+	if commit, err := git.GetLastCommit(); err == nil {
+		msg.head = &commit
+	}
+
+	remotes, err := git.ListRemotes()
+	if err != nil {
+		return errMsg{err}
+	}
+	msg.remotes = remotes
+	return msg
+}
+
+func (a *AboutView) Update(msg tea.Msg) (*AboutView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case aboutLoadedMsg:
+		a.info = &msg
+		a.loader.stop()
+
+	case errMsg:
+		a.err = msg.err
+		a.loader.stop()
+
+	case spinner.TickMsg:
+		return a, a.loader.tick(msg)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q":
+			return a, func() tea.Msg { return aboutCloseMsg{} }
+
+		case "y":
+			// Copy as plain text, ready to paste into a bug report
+			if a.info == nil {
+				return a, nil
+			}
+			var text strings.Builder
+			for _, row := range a.rows() {
+				text.WriteString(fmt.Sprintf("%-12s%s\n", row[0], row[1]))
+			}
+			if err := clipboard.WriteAll(text.String()); err != nil {
+				a.err = fmt.Errorf("no clipboard available: %w", err)
+				a.status = ""
+				return a, nil
+			}
+			a.err = nil
+			a.status = "Copied to clipboard"
+		}
+
+	case tea.WindowSizeMsg:
+		a.width = msg.Width
+		a.height = msg.Height
+	}
+
+	return a, nil
+}
+
+// rows returns the label and value of each line the view shows. A label is
+// empty on the lines continuing the one above it.
+func (a *AboutView) rows() [][2]string {
+	info := a.info
+	rows := [][2]string{
+		{"GitGoblin", a.version},
+		{"Git", info.gitVersion},
+		{"Repository", info.root},
+	}
+	// Worktrees and submodules keep their git directory elsewhere
+	if info.gitDir != filepath.Join(info.root, ".git") {
+		rows = append(rows, [2]string{"Git dir", info.gitDir})
+	}
+
+	branch := info.branch
+	if info.detached {
+		branch = "detached @ " + info.branch
+	}
+	rows = append(rows, [2]string{"Branch", branch})
+
+	head := "no commits yet"
+	if info.head != nil {
+		head = info.head.Hash + " " + info.head.Message
+	}
+	rows = append(rows, [2]string{"HEAD", head})
+
+	if len(info.remotes) == 0 {
+		rows = append(rows, [2]string{"Remotes", "none"})
+	}
+	for i, remote := range info.remotes {
+		label := ""
+		if i == 0 {
+			label = "Remotes"
+		}
+		line := remote.Name
+		if remote.Name == info.defaultRemote {
+			line += " (default)"
+		}
+		line += "  " + remote.FetchURL
+		if remote.PushURL != "" && remote.PushURL != remote.FetchURL {
+			line += "  push: " + remote.PushURL
+		}
+		rows = append(rows, [2]string{label, line})
+	}
+
+	return rows
+}
+
+func (a *AboutView) View() string {
+	grayStyle := lipgloss.NewStyle().Foreground(a.theme.Muted)
+	errorStyle := lipgloss.NewStyle().Foreground(a.theme.StatusError)
+
+	if a.info == nil {
+		if a.err != nil {
+			return errorStyle.Render(fmt.Sprintf("Error: %v", a.err)) + "\n\n" + grayStyle.Render("esc: back")
+		}
+		return a.loader.view("Loading repository info...")
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(a.theme.Accent).
+		Bold(true).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().Foreground(a.theme.Secondary).Width(12)
+	valueStyle := lipgloss.NewStyle().Foreground(a.theme.Text)
+	statusStyle := lipgloss.NewStyle().Foreground(a.theme.StatusOK)
+
+	var out strings.Builder
+
+	out.WriteString(headerStyle.Render("About") + "\n")
+	for _, row := range a.rows() {
+		out.WriteString("  " + labelStyle.Render(row[0]) + valueStyle.Render(row[1]) + "\n")
+	}
+
+	if a.err != nil {
+		out.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %v", a.err)) + "\n")
+	} else if a.status != "" {
+		out.WriteString("\n" + statusStyle.Render(a.status) + "\n")
+	}
+
+	out.WriteString("\n" + grayStyle.Render("y: copy • esc: back"))
+
+	return out.String()
+}
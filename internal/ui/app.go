@@ -1,11 +1,19 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
 	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
 
 type viewMode int
@@ -14,6 +22,15 @@ const (
 	viewDashboard viewMode = iota
 	viewBranchInput
 	viewCommitFlow
+	viewMaintenance
+	viewStash
+	viewGraph
+	viewBranches
+	viewStaging
+	viewReflog
+	viewRemotes
+	viewBranchDiff
+	viewAbout
 )
 
 type errMsg struct {
@@ -24,104 +41,923 @@ type tickMsg time.Time
 
 type clearStatusMsg struct{}
 
+// branchSwitchedMsg reports the result of checking out a branch
+type branchSwitchedMsg struct {
+	name    string
+	stashed bool // changes were stashed before switching
+	err     error
+}
+
+// defaultBranchCheckMsg carries what's needed to decide whether switching
+// to the default branch needs confirmation
+type defaultBranchCheckMsg struct {
+	target string
+	dirty  bool
+	err    error
+}
+
+// changesMovedMsg reports the result of moving changes to another branch
+type changesMovedMsg struct {
+	branch string
+	err    error
+}
+
+// stashPopAbortedMsg reports the result of undoing a conflicted stash pop
+type stashPopAbortedMsg struct {
+	index int
+	err   error
+}
+
+// cherryPickFinishedMsg reports the result of continuing or aborting an
+// in-progress cherry-pick
+type cherryPickFinishedMsg struct {
+	aborted bool
+	err     error
+}
+
+// revertFinishedMsg reports the result of continuing or aborting an
+// in-progress revert
+type revertFinishedMsg struct {
+	aborted bool
+	err     error
+}
+
+// mergeAbortedMsg reports the result of aborting an in-progress merge
+type mergeAbortedMsg struct {
+	err error
+}
+
+// resolveConflictsMsg opens the staging view to resolve conflicts by hand
+type resolveConflictsMsg struct{}
+
+// fileEditedMsg reports that the editor opened from the dashboard exited
+type fileEditedMsg struct {
+	err error
+}
+
+// stashPushedMsg reports the result of stashing the working tree
+type stashPushedMsg struct {
+	err error
+}
+
+// syncStartMsg carries the upstream lookup needed before a push or pull
+type syncStartMsg struct {
+	push         bool
+	branch       string
+	remote       string // empty when the branch has no upstream
+	remoteBranch string
+	first        string // remote a first push goes to
+	err          error
+}
+
+// branchAtCommitMsg reports the result of creating a branch at a commit
+// picked in the graph
+type branchAtCommitMsg struct {
+	name  string
+	short string
+	err   error
+}
+
+// syncDoneMsg reports the result of a push or pull
+type syncDoneMsg struct {
+	status string
+	err    error
+}
+
+// quitCheckMsg carries what quitting would leave behind, to decide
+// whether to ask first
+type quitCheckMsg struct {
+	always bool // ask even when nothing would be left behind
+	dirty  bool
+	draft  bool // a commit message has been typed
+}
+
+type prURLMsg struct {
+	url  string
+	open bool
+	err  error
+}
+
 type Model struct {
+	cfg         *config.Config
+	theme       *Theme
 	dashboard   *DashboardView
 	branchInput *BranchInputView
 	commitFlow  *CommitFlowView
+	maintenance *MaintenanceView
+	stash       *StashView
+	graph       *GraphView
+	branches    *BranchView
+	staging     *StagingView
+	reflog      *ReflogView
+	remotes     *RemoteView
+	branchDiff  *BranchDiffView
+	about       *AboutView
+	confirm     *ConfirmView
+	graphScope  *graphScope // refs the graph shows, kept while GitGoblin runs
+	commitDraft string      // message left in a cancelled commit flow
+	sync        loader      // spins next to the status while a pull or push runs
+	version     string      // GitGoblin's own, shown in the about view
+	ticking     bool        // the next auto-refresh tick is scheduled
 	viewMode    viewMode
 	statusMsg   string
 	statusStyle lipgloss.Style
 	err         error
 }
 
-func NewModel() Model {
+// NewModel builds the app for repo, which every git command then runs in
+func NewModel(repo *git.Repo, cfg *config.Config, theme *Theme) Model {
+	git.Use(repo)
 	return Model{
-		dashboard: NewDashboardView(),
-		viewMode:  viewDashboard,
+		cfg:        cfg,
+		theme:      theme,
+		dashboard:  NewDashboardView(cfg, theme),
+		sync:       newLoader(theme),
+		graphScope: &graphScope{},
+		viewMode:   viewDashboard,
 	}
 }
 
+// WithVersion sets the GitGoblin version the about view shows
+func (m Model) WithVersion(version string) Model {
+	m.version = version
+	return m
+}
+
+// OpenFile starts the app in the staging view with path selected and its diff shown
+func (m Model) OpenFile(path string) Model {
+	m.staging = NewStagingView(m.cfg, m.theme)
+	m.staging.FocusFile(path)
+	m.viewMode = viewStaging
+	return m
+}
+
+// RestoreSession reopens the view left in an earlier session, with its
+// selection, instead of starting on the dashboard
+func (m Model) RestoreSession(session config.Session) Model {
+	switch session.View {
+	case "graph":
+		m.graph = NewGraphView(m.cfg, m.theme)
+		m.graph.scope = m.graphScope
+		m.graph.Select(session.Commit)
+		m.viewMode = viewGraph
+	case "branches":
+		m.branches = NewBranchView(m.cfg, m.theme)
+		m.branches.Select(session.Branch)
+		m.viewMode = viewBranches
+	}
+	return m
+}
+
+// Session returns the view and selection to restore on the next start.
+// Views other than the graph and branch list come back as the dashboard.
+func (m Model) Session() config.Session {
+	session := config.Session{View: "dashboard"}
+	switch m.viewMode {
+	case viewGraph:
+		// A file's history or a commit picker was opened from another view
+		if m.graph == nil || m.graph.path != "" || m.graph.picking {
+			break
+		}
+		session.View = "graph"
+		if commit := m.graph.SelectedCommit(); commit != nil {
+			session.Commit = commit.Hash
+		}
+	case viewBranches:
+		if m.branches == nil {
+			break
+		}
+		session.View = "branches"
+		if branch := m.branches.SelectedBranch(); branch != nil {
+			session.Branch = branch.Name
+		}
+	}
+	return session
+}
+
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		m.dashboard.Init(),
-		tickCmd(),
-	)
+	// Auto-refresh ticks start with the first message, see keepTicking
+	cmds := []tea.Cmd{m.dashboard.Init()}
+	if m.staging != nil {
+		cmds = append(cmds, m.staging.Init())
+	}
+	if m.graph != nil {
+		cmds = append(cmds, m.graph.Init())
+	}
+	if m.branches != nil {
+		cmds = append(cmds, m.branches.Init())
+	}
+	return tea.Batch(cmds...)
 }
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Second*2, func(t time.Time) tea.Msg {
+func tickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	return m.keepTicking(cmd)
+}
+
+// keepTicking schedules the next auto-refresh tick while the dashboard is
+// shown and not paused. Ticks stop in other views, such as the commit flow,
+// and start again once the dashboard is back.
+func (m Model) keepTicking(cmd tea.Cmd) (Model, tea.Cmd) {
+	if m.ticking || m.viewMode != viewDashboard || m.dashboard.Paused() {
+		return m, cmd
+	}
+	m.ticking = true
+	return m, tea.Batch(cmd, tickCmd(m.refreshInterval()))
+}
+
+// refreshInterval returns the configured auto-refresh interval, or the
+// default when auto-refresh was paused from the start by setting it to zero
+func (m Model) refreshInterval() time.Duration {
+	if m.cfg.RefreshInterval > 0 {
+		return m.cfg.RefreshInterval
+	}
+	return config.DefaultRefreshInterval
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			// A second ctrl+c while a confirmation is open quits anyway
+			if !m.cfg.ConfirmQuit || m.confirm != nil {
+				return m, tea.Quit
+			}
+			return m, m.checkQuit(false)
+		}
+
+		// An open confirmation captures all other keys
+		if m.confirm != nil {
+			m.confirm, cmd = m.confirm.Update(msg)
+			return m, cmd
+		}
+
+		// Remappable dashboard keys take precedence over the fixed ones below
+		if keys := m.cfg.Keys; m.viewMode == viewDashboard {
+			switch key := msg.String(); {
+			case keys.Quit.Matches(key):
+				if !m.cfg.ConfirmQuit {
+					return m, tea.Quit
+				}
+				return m, m.checkQuit(false)
+			case keys.NewBranch.Matches(key):
+				m.branchInput = NewBranchInputView(m.theme)
+				m.viewMode = viewBranchInput
+				m.statusMsg = ""
+				return m, m.branchInput.Init()
+			case keys.Commit.Matches(key):
+				m.commitFlow = NewCommitFlowView(m.cfg, m.theme)
+				m.commitFlow.RestoreDraft(m.commitDraft)
+				m.viewMode = viewCommitFlow
+				m.statusMsg = ""
+				return m, m.commitFlow.Init()
+			case keys.Down.Matches(key):
+				// Scroll a file list taller than the screen
+				m.dashboard.ScrollFiles(1)
+				return m, nil
+			case keys.Up.Matches(key):
+				m.dashboard.ScrollFiles(-1)
+				return m, nil
+			case keys.Top.Matches(key):
+				m.dashboard.ScrollFilesToEdge(false)
+				return m, nil
+			case keys.Bottom.Matches(key):
+				m.dashboard.ScrollFilesToEdge(true)
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
-		case "ctrl+c":
-			return m, tea.Quit
-		case "n":
-			// Only handle 'n' in dashboard mode
+		case "l":
+			// Only handle 'l' in dashboard mode
+			if m.viewMode == viewDashboard {
+				if m.dashboard.emptyRepo {
+					return m, m.setStatus("No commits yet, make your first commit to see the graph", false)
+				}
+				m.graph = NewGraphView(m.cfg, m.theme)
+				m.graph.scope = m.graphScope
+				m.graph, _ = m.graph.Update(m.windowSize())
+				m.viewMode = viewGraph
+				m.statusMsg = ""
+				return m, m.graph.Init()
+			}
+		case "b":
+			// Only handle 'b' in dashboard mode
+			if m.viewMode == viewDashboard {
+				m.branches = NewBranchView(m.cfg, m.theme)
+				m.branches, _ = m.branches.Update(m.windowSize())
+				m.viewMode = viewBranches
+				m.statusMsg = ""
+				return m, m.branches.Init()
+			}
+		case "u", "U":
+			// Copy (u) or open (U) the pull request URL for the current branch
+			if m.viewMode == viewDashboard {
+				return m, m.pullRequestURL(msg.String() == "U")
+			}
+		case "M":
+			// Only handle 'M' in dashboard mode
+			if m.viewMode == viewDashboard {
+				m.maintenance = NewMaintenanceView(m.cfg, m.theme)
+				m.maintenance, _ = m.maintenance.Update(m.windowSize())
+				m.viewMode = viewMaintenance
+				m.statusMsg = ""
+				return m, m.maintenance.Init()
+			}
+		case "s":
+			// Stash the working tree, including untracked files
+			if m.viewMode == viewDashboard {
+				return m, func() tea.Msg {
+					return stashPushedMsg{err: git.StashPush("", true)}
+				}
+			}
+		case "S":
+			// Only handle 'S' in dashboard mode
+			if m.viewMode == viewDashboard {
+				m.stash = NewStashView(m.cfg, m.theme)
+				m.stash, _ = m.stash.Update(m.windowSize())
+				m.viewMode = viewStash
+				m.statusMsg = ""
+				return m, m.stash.Init()
+			}
+		case "R":
+			// Browse the reflog to recover lost commits
 			if m.viewMode == viewDashboard {
-				m.branchInput = NewBranchInputView()
+				m.reflog = NewReflogView(m.cfg, m.theme)
+				m.reflog, _ = m.reflog.Update(m.windowSize())
+				m.viewMode = viewReflog
+				m.statusMsg = ""
+				return m, m.reflog.Init()
+			}
+		case "o":
+			// Manage remotes and pick the default one
+			if m.viewMode == viewDashboard {
+				m.remotes = NewRemoteView(m.cfg, m.theme)
+				m.remotes, _ = m.remotes.Update(m.windowSize())
+				m.viewMode = viewRemotes
+				m.statusMsg = ""
+				return m, m.remotes.Init()
+			}
+		case "D":
+			// Review everything the branch changed since the default branch
+			if m.viewMode == viewDashboard {
+				m.branchDiff = NewBranchDiffView(m.cfg, m.theme)
+				m.branchDiff, _ = m.branchDiff.Update(m.windowSize())
+				m.viewMode = viewBranchDiff
+				m.statusMsg = ""
+				return m, m.branchDiff.Init()
+			}
+		case "I":
+			// Show versions and which repository and remotes are in use
+			if m.viewMode == viewDashboard {
+				m.about = NewAboutView(m.version, m.theme)
+				m.about, _ = m.about.Update(m.windowSize())
+				m.viewMode = viewAbout
+				m.statusMsg = ""
+				return m, m.about.Init()
+			}
+		case "T":
+			// Move uncommitted changes to another branch
+			if m.viewMode == viewDashboard {
+				m.branchInput = NewMoveChangesInputView(m.theme)
 				m.viewMode = viewBranchInput
 				m.statusMsg = ""
 				return m, m.branchInput.Init()
 			}
-		case "c":
-			// Only handle 'c' in dashboard mode
+		case "p", "P":
+			// Fetch and pull (p) or push (P) the current branch
 			if m.viewMode == viewDashboard {
-				m.commitFlow = NewCommitFlowView()
-				m.viewMode = viewCommitFlow
-				m.statusMsg = ""
-				return m, m.commitFlow.Init()
+				return m, m.startSync(msg.String() == "P")
+			}
+		case "H":
+			// Jump to the default branch, from the views listing commits,
+			// branches or files too, returning to the dashboard for it
+			if m.canJumpToDefaultBranch() {
+				m.viewMode = viewDashboard
+				m.graph = nil
+				m.branches = nil
+				m.staging = nil
+				return m, m.checkDefaultBranchSwitch()
+			}
+		case "w":
+			// Toggle wrapping of long branch names
+			if m.viewMode == viewDashboard {
+				m.dashboard.ToggleBranchWrap()
+				return m, nil
+			}
+		case "=":
+			// Toggle absolute/net line stats
+			if m.viewMode == viewDashboard {
+				m.dashboard.ToggleDeltaStats()
+				return m, nil
+			}
+		case "t":
+			// Toggle counting untracked files in the file count
+			if m.viewMode == viewDashboard {
+				m.dashboard.ToggleTrackedOnly()
+				return m, nil
+			}
+		case "i":
+			// Toggle listing ignored files
+			if m.viewMode == viewDashboard {
+				return m, m.dashboard.ToggleIgnored()
+			}
+		case "e":
+			// Open the changed file in the editor, the dashboard list has no
+			// selection so with several of them pick one in the staging view
+			if m.viewMode == viewDashboard {
+				return m, m.editChangedFile()
+			}
+		case "C":
+			// Continue a cherry-pick or revert once its conflicts are resolved
+			if m.viewMode != viewDashboard {
+				break
+			}
+			switch m.dashboard.repoState.Operation {
+			case models.OperationCherryPick:
+				return m, func() tea.Msg {
+					return cherryPickFinishedMsg{err: git.CherryPickContinue()}
+				}
+			case models.OperationRevert:
+				return m, func() tea.Msg {
+					return revertFinishedMsg{err: git.RevertContinue()}
+				}
+			}
+		case "A":
+			// Abort an in-progress cherry-pick, revert or merge
+			if m.viewMode != viewDashboard {
+				break
+			}
+			switch m.dashboard.repoState.Operation {
+			case models.OperationCherryPick:
+				return m, requestConfirm("Abort the cherry-pick? Changes made while resolving it will be lost.", func() tea.Msg {
+					return cherryPickFinishedMsg{aborted: true, err: git.CherryPickAbort()}
+				}, nil)
+			case models.OperationRevert:
+				return m, requestConfirm("Abort the revert? Changes made while resolving it will be lost.", func() tea.Msg {
+					return revertFinishedMsg{aborted: true, err: git.RevertAbort()}
+				}, nil)
+			case models.OperationMerge:
+				return m, requestConfirm("Abort the merge? Changes made while resolving it will be lost.", func() tea.Msg {
+					return mergeAbortedMsg{err: git.MergeAbort()}
+				}, nil)
+			}
+		case ".":
+			// Toggle files hidden by exclude_paths
+			if m.viewMode == viewDashboard {
+				m.dashboard.ToggleHidden()
+				return m, nil
+			}
+		case "q":
+			// Quit, unlike the quit keys always asking first
+			if m.viewMode == viewDashboard {
+				return m, m.checkQuit(true)
+			}
+		case "#":
+			// Toggle the commit, branch, tag and contributor counts
+			if m.viewMode == viewDashboard {
+				return m, m.dashboard.ToggleStats()
+			}
+		case "r":
+			// Refresh now, e.g. while auto-refresh is paused
+			if m.viewMode == viewDashboard {
+				return m, m.dashboard.refresh()
+			}
+		case "a":
+			// Pause or resume auto-refresh
+			if m.viewMode == viewDashboard {
+				m.dashboard.TogglePaused()
+				if m.dashboard.Paused() {
+					return m, m.setStatus("Auto-refresh paused, r: refresh", false)
+				}
+				return m, tea.Batch(
+					m.dashboard.refresh(),
+					m.setStatus("Auto-refresh resumed", false),
+				)
 			}
 		}
 
+	case prURLMsg:
+		if msg.err != nil {
+			return m, m.setStatus("Error: "+msg.err.Error(), true)
+		}
+		if msg.open {
+			if err := openURL(msg.url); err != nil {
+				return m, m.setStatus("Could not open browser: "+msg.url, true)
+			}
+			return m, m.setStatus("Opened "+msg.url, false)
+		}
+		if err := clipboard.WriteAll(msg.url); err != nil {
+			// No clipboard available, show the URL so it can be copied by hand
+			return m, m.setStatus(msg.url, false)
+		}
+		return m, m.setStatus("Copied "+msg.url, false)
+
 	case branchInputDoneMsg:
+		if msg.rename != "" {
+			// Back to the branch list, which reports how the rename went
+			m.viewMode = viewBranches
+			m.branchInput = nil
+			return m, renameBranchCmd(msg.rename, msg.name)
+		}
+		if msg.move {
+			m.viewMode = viewDashboard
+			m.branchInput = nil
+			return m, moveChangesCmd(msg.name)
+		}
+		if msg.at != "" {
+			// The graph's refs are stale now, reopening it shows the branch
+			m.viewMode = viewDashboard
+			m.branchInput = nil
+			m.graph = nil
+			return m, branchAtCommitCmd(msg.name, msg.at)
+		}
+
 		// Create the branch
 		err := git.CreateBranchFromDefault(msg.name)
 		m.viewMode = viewDashboard
 		m.branchInput = nil
 		if err != nil {
 			m.statusMsg = "Error: " + err.Error()
-			m.statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+			m.statusStyle = lipgloss.NewStyle().Foreground(m.theme.StatusError)
 		} else {
 			m.statusMsg = "Created branch: " + msg.name
-			m.statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+			m.statusStyle = lipgloss.NewStyle().Foreground(m.theme.StatusOK)
 		}
 		return m, tea.Batch(
-			m.dashboard.loadData(),
+			m.dashboard.refresh(),
 			tea.Tick(time.Second*3, func(t time.Time) tea.Msg { return clearStatusMsg{} }),
 		)
 
 	case branchInputCancelMsg:
 		m.viewMode = viewDashboard
+		if m.branches != nil {
+			// Cancelled a rename started from the branch list
+			m.viewMode = viewBranches
+		} else if m.graph != nil {
+			// Cancelled a branch started from the graph
+			m.viewMode = viewGraph
+		}
 		m.branchInput = nil
 		return m, nil
 
+	case branchAtCommitRequestMsg:
+		m.branchInput = NewBranchAtCommitInputView(msg.hash, msg.short, m.theme)
+		m.branchInput, _ = m.branchInput.Update(m.windowSize())
+		m.viewMode = viewBranchInput
+		return m, m.branchInput.Init()
+
+	case branchAtCommitMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus(fmt.Sprintf("Created branch %s at %s", msg.name, msg.short), false), m.dashboard.refresh())
+
+	case branchRenameRequestMsg:
+		m.branchInput = NewRenameBranchInputView(msg.name, m.theme)
+		m.branchInput, _ = m.branchInput.Update(m.windowSize())
+		m.viewMode = viewBranchInput
+		return m, m.branchInput.Init()
+
 	case commitFlowDoneMsg:
 		m.viewMode = viewDashboard
 		m.commitFlow = nil
-		m.statusMsg = "Committed: " + msg.message
-		m.statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+		m.commitDraft = ""
+		// Only the subject fits in the status line
+		subject := strings.SplitN(msg.message, "\n", 2)[0]
+		m.statusMsg = "Committed: " + subject
+		if msg.amended {
+			m.statusMsg = "Amended: " + subject
+		}
+		m.statusStyle = lipgloss.NewStyle().Foreground(m.theme.StatusOK)
 		return m, tea.Batch(
-			m.dashboard.loadData(),
+			m.dashboard.refresh(),
 			tea.Tick(time.Second*3, func(t time.Time) tea.Msg { return clearStatusMsg{} }),
 		)
 
 	case commitFlowCancelMsg:
 		m.viewMode = viewDashboard
 		m.commitFlow = nil
-		return m, m.dashboard.loadData()
+		m.commitDraft = msg.draft
+		return m, m.dashboard.refresh()
+
+	case confirmRequestMsg:
+		m.confirm = NewConfirmView(msg.prompt, msg.onYes, msg.onNo, m.theme)
+		m.confirm, _ = m.confirm.Update(m.windowSize())
+		return m, nil
+
+	case confirmMsg:
+		if m.confirm == nil {
+			return m, nil
+		}
+		cmd = m.confirm.Result(msg.confirmed)
+		m.confirm = nil
+		return m, cmd
+
+	case quitCheckMsg:
+		var prompt string
+		switch {
+		case msg.dirty && msg.draft:
+			prompt = "You have uncommitted changes and an unfinished commit message, which will be lost. Quit anyway?"
+		case msg.draft:
+			prompt = "Your unfinished commit message will be lost. Quit anyway?"
+		case msg.dirty:
+			prompt = "You have uncommitted changes. Quit anyway?"
+		case msg.always:
+			prompt = "Quit GitGoblin?"
+		default:
+			return m, tea.Quit
+		}
+		m.confirm = NewConfirmView(prompt, tea.Quit, nil, m.theme)
+		m.confirm, _ = m.confirm.Update(m.windowSize())
+		return m, nil
+
+	case defaultBranchCheckMsg:
+		if msg.err != nil {
+			return m, m.setStatus("Error: "+msg.err.Error(), true)
+		}
+		if msg.dirty {
+			// Declining the stash offers the plain checkout instead
+			m.confirm = NewConfirmView(
+				fmt.Sprintf("You have uncommitted changes. Stash them and switch to %s? Pop them back later from the stash list. Answer n to switch without stashing.", msg.target),
+				stashAndSwitchCmd(msg.target),
+				requestConfirm(
+					fmt.Sprintf("Switch to %s without stashing? Changes that conflict will stop the checkout.", msg.target),
+					switchBranchCmd(msg.target),
+					nil,
+				),
+				m.theme,
+			)
+			m.confirm, _ = m.confirm.Update(m.windowSize())
+			return m, nil
+		}
+		return m, switchBranchCmd(msg.target)
+
+	case branchSwitchedMsg:
+		if msg.err != nil && m.viewMode == viewBranches {
+			// Keep the branch list open so git's message can be read
+			break
+		}
+		if m.viewMode == viewBranches {
+			m.viewMode = viewDashboard
+			m.branches = nil
+		}
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		status := "Switched to " + msg.name
+		if msg.stashed {
+			status += ", changes stashed in stash@{0}"
+		}
+		return m, tea.Batch(m.setStatus(status, false), m.dashboard.refresh())
+
+	case syncStartMsg:
+		if msg.err != nil {
+			return m, m.setStatus("Error: "+msg.err.Error(), true)
+		}
+		if !msg.push {
+			if msg.remote == "" {
+				return m, m.setStatus(msg.branch+" has no upstream to pull from", true)
+			}
+			m.statusMsg = "Pulling " + msg.remote + "/" + msg.remoteBranch + "..."
+			m.statusStyle = lipgloss.NewStyle().Foreground(m.theme.Muted)
+			return m, m.sync.start(pullCmd(msg.remote, msg.remoteBranch))
+		}
+		if msg.remote == "" {
+			// First push of this branch
+			m.confirm = NewConfirmView(
+				fmt.Sprintf("%s has no upstream. Push it to %s/%s and set it as upstream?", msg.branch, msg.first, msg.branch),
+				pushCmd(msg.first, msg.branch, msg.branch, true),
+				nil,
+				m.theme,
+			)
+			m.confirm, _ = m.confirm.Update(m.windowSize())
+			return m, nil
+		}
+		if m.dashboard.aheadCount == 0 {
+			return m, m.setStatus("Nothing to push", false)
+		}
+		m.statusMsg = "Pushing to " + msg.remote + "/" + msg.remoteBranch + "..."
+		m.statusStyle = lipgloss.NewStyle().Foreground(m.theme.Muted)
+		return m, m.sync.start(pushCmd(msg.remote, msg.branch, msg.remoteBranch, false))
+
+	case syncDoneMsg:
+		m.sync.stop()
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus(msg.status, false), m.dashboard.refresh())
+
+	case changesMovedMsg:
+		if errors.Is(msg.err, git.ErrStashPopConflict) {
+			m.confirmStashConflict(msg.err, 0)
+			return m, m.dashboard.refresh()
+		}
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus("Moved changes to "+msg.branch, false), m.dashboard.refresh())
+
+	case fileEditedMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, m.dashboard.refresh()
+
+	case stashPushedMsg:
+		if msg.err != nil {
+			return m, m.setStatus("Error: "+msg.err.Error(), true)
+		}
+		return m, tea.Batch(m.setStatus("Stashed changes", false), m.dashboard.refresh())
+
+	case stashPoppedMsg:
+		m.viewMode = viewDashboard
+		m.stash = nil
+		if errors.Is(msg.err, git.ErrStashPopConflict) {
+			m.confirmStashConflict(msg.err, msg.index)
+			return m, m.dashboard.refresh()
+		}
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		status := fmt.Sprintf("Popped stash@{%d}", msg.index)
+		if msg.applied {
+			status = fmt.Sprintf("Applied stash@{%d}, it is still in the stash list", msg.index)
+		}
+		return m, tea.Batch(m.setStatus(status, false), m.dashboard.refresh())
+
+	case stashPopAbortedMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		status := fmt.Sprintf("Undid the stash pop, changes are kept in stash@{%d}", msg.index)
+		return m, tea.Batch(m.setStatus(status, false), m.dashboard.refresh())
+
+	case cherryPickedMsg:
+		m.viewMode = viewDashboard
+		m.graph = nil
+		if errors.Is(msg.err, git.ErrCherryPickConflict) {
+			m.confirmConflicts(
+				fmt.Sprintf("Cherry-picking %s stopped on conflicts.", msg.hash),
+				"press C on the dashboard to continue or A to abort it",
+			)
+			return m, m.dashboard.refresh()
+		}
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus("Cherry-picked "+msg.hash, false), m.dashboard.refresh())
+
+	case revertedMsg:
+		m.viewMode = viewDashboard
+		m.graph = nil
+		if errors.Is(msg.err, git.ErrRevertConflict) {
+			m.confirmConflicts(
+				fmt.Sprintf("Reverting %s stopped on conflicts.", msg.hash),
+				"press C on the dashboard to continue or A to abort it",
+			)
+			return m, m.dashboard.refresh()
+		}
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus("Reverted "+msg.hash, false), m.dashboard.refresh())
+
+	case authorAmendedMsg:
+		// HEAD was rewritten, so the graph showing the old commit is stale
+		m.viewMode = viewDashboard
+		m.graph = nil
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus("Amended the author of HEAD to "+msg.author, false), m.dashboard.refresh())
+
+	case fixupCommittedMsg:
+		// The new commit sits on top of the branch the graph showed
+		m.viewMode = viewDashboard
+		m.graph = nil
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus("Committed a fixup of "+msg.hash+", squash it in with git rebase -i --autosquash", false), m.dashboard.refresh())
+
+	case commitCheckedOutMsg:
+		m.viewMode = viewDashboard
+		m.graph = nil
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus("Checked out "+msg.hash+", HEAD is detached", false), m.dashboard.refresh())
+
+	case branchMergedMsg:
+		var conflict *git.MergeConflictError
+		if errors.As(msg.err, &conflict) {
+			m.viewMode = viewDashboard
+			m.branches = nil
+			m.confirmConflicts(
+				fmt.Sprintf("Merging %s stopped on conflicts in:\n\n  %s", msg.name, strings.Join(conflict.Files, "\n  ")),
+				"commit once they are resolved or press A on the dashboard to abort the merge",
+			)
+			return m, m.dashboard.refresh()
+		}
+		if msg.err != nil {
+			// Let the branch view show the error
+			break
+		}
+		m.viewMode = viewDashboard
+		m.branches = nil
+		return m, tea.Batch(m.setStatus("Merged "+msg.name, false), m.dashboard.refresh())
+
+	case mergeAbortedMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		return m, tea.Batch(m.setStatus("Merge aborted", false), m.dashboard.refresh())
+
+	case cherryPickFinishedMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		status := "Cherry-pick committed"
+		if msg.aborted {
+			status = "Cherry-pick aborted"
+		}
+		return m, tea.Batch(m.setStatus(status, false), m.dashboard.refresh())
+
+	case revertFinishedMsg:
+		if msg.err != nil {
+			return m, tea.Batch(m.setStatus("Error: "+msg.err.Error(), true), m.dashboard.refresh())
+		}
+		status := "Revert committed"
+		if msg.aborted {
+			status = "Revert aborted"
+		}
+		return m, tea.Batch(m.setStatus(status, false), m.dashboard.refresh())
+
+	case resolveConflictsMsg:
+		m.staging = NewStagingView(m.cfg, m.theme)
+		m.staging, _ = m.staging.Update(m.windowSize())
+		m.viewMode = viewStaging
+		return m, m.staging.Init()
+
+	case stagingCloseMsg:
+		m.viewMode = viewDashboard
+		m.staging = nil
+		return m, m.dashboard.refresh()
+
+	case stashCloseMsg:
+		m.viewMode = viewDashboard
+		m.stash = nil
+		return m, m.dashboard.refresh()
+
+	case graphCloseMsg:
+		m.viewMode = viewDashboard
+		m.graph = nil
+		return m, m.dashboard.refresh()
+
+	case branchViewCloseMsg:
+		m.viewMode = viewDashboard
+		m.branches = nil
+		return m, m.dashboard.refresh()
+
+	case maintenanceCloseMsg:
+		m.viewMode = viewDashboard
+		m.maintenance = nil
+		return m, m.dashboard.refresh()
+
+	case reflogCloseMsg:
+		m.viewMode = viewDashboard
+		m.reflog = nil
+		return m, m.dashboard.refresh()
+
+	case remoteViewCloseMsg:
+		m.viewMode = viewDashboard
+		m.remotes = nil
+		return m, m.dashboard.refresh()
+
+	case branchDiffCloseMsg:
+		m.viewMode = viewDashboard
+		m.branchDiff = nil
+		return m, m.dashboard.refresh()
+
+	case aboutCloseMsg:
+		m.viewMode = viewDashboard
+		m.about = nil
+		return m, m.dashboard.refresh()
 
 	case clearStatusMsg:
 		m.statusMsg = ""
 		return m, nil
 
-	case dashboardDataMsg:
+	case dashboardDataMsg, historyStatsMsg:
 		// Forward to dashboard
 		m.dashboard, cmd = m.dashboard.Update(msg)
 		return m, cmd
@@ -135,21 +971,63 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.commitFlow != nil {
 			m.commitFlow, _ = m.commitFlow.Update(msg)
 		}
+		if m.maintenance != nil {
+			m.maintenance, _ = m.maintenance.Update(msg)
+		}
+		if m.stash != nil {
+			m.stash, _ = m.stash.Update(msg)
+		}
+		if m.graph != nil {
+			m.graph, _ = m.graph.Update(msg)
+		}
+		if m.branches != nil {
+			m.branches, _ = m.branches.Update(msg)
+		}
+		if m.staging != nil {
+			m.staging, _ = m.staging.Update(msg)
+		}
+		if m.reflog != nil {
+			m.reflog, _ = m.reflog.Update(msg)
+		}
+		if m.remotes != nil {
+			m.remotes, _ = m.remotes.Update(msg)
+		}
+		if m.branchDiff != nil {
+			m.branchDiff, _ = m.branchDiff.Update(msg)
+		}
+		if m.about != nil {
+			m.about, _ = m.about.Update(msg)
+		}
+		if m.confirm != nil {
+			m.confirm, _ = m.confirm.Update(msg)
+		}
 		return m, cmd
 
 	case tickMsg:
-		// Auto-refresh on tick (only in dashboard mode)
-		if m.viewMode == viewDashboard {
-			return m, tea.Batch(
-				m.dashboard.loadData(),
-				tickCmd(),
-			)
+		// Auto-refresh on tick (only in dashboard mode), skipping ticks
+		// while the previous refresh is still running
+		m.ticking = false
+		if m.viewMode == viewDashboard && !m.dashboard.Paused() && !m.dashboard.Refreshing() {
+			return m, m.dashboard.refresh()
 		}
-		return m, tickCmd()
+		return m, nil
+
+	case spinner.TickMsg:
+		if msg.ID == m.dashboard.spinner.ID() {
+			m.dashboard, cmd = m.dashboard.Update(msg)
+			return m, cmd
+		}
+		if msg.ID == m.sync.spinner.ID() {
+			return m, m.sync.tick(msg)
+		}
+		// Otherwise it belongs to the active view
 
 	case errMsg:
 		m.err = msg.err
-		return m, nil
+		if m.viewMode == viewDashboard {
+			return m, m.setStatus("Error: "+msg.err.Error(), true)
+		}
+		// Otherwise let the active view show it
 	}
 
 	// Forward messages to active view
@@ -161,11 +1039,286 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.commitFlow, cmd = m.commitFlow.Update(msg)
 		return m, cmd
 	}
+	if m.viewMode == viewMaintenance && m.maintenance != nil {
+		m.maintenance, cmd = m.maintenance.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewStash && m.stash != nil {
+		m.stash, cmd = m.stash.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewGraph && m.graph != nil {
+		m.graph, cmd = m.graph.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewBranches && m.branches != nil {
+		m.branches, cmd = m.branches.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewStaging && m.staging != nil {
+		m.staging, cmd = m.staging.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewReflog && m.reflog != nil {
+		m.reflog, cmd = m.reflog.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewRemotes && m.remotes != nil {
+		m.remotes, cmd = m.remotes.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewBranchDiff && m.branchDiff != nil {
+		m.branchDiff, cmd = m.branchDiff.Update(msg)
+		return m, cmd
+	}
+	if m.viewMode == viewAbout && m.about != nil {
+		m.about, cmd = m.about.Update(msg)
+		return m, cmd
+	}
 
 	return m, cmd
 }
 
+// windowSize returns the last known terminal size, for sizing newly opened views
+func (m Model) windowSize() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{Width: m.dashboard.width, Height: m.dashboard.height}
+}
+
+// setStatus shows a transient message below the dashboard
+func (m *Model) setStatus(text string, isErr bool) tea.Cmd {
+	m.statusMsg = text
+	if isErr {
+		m.statusStyle = lipgloss.NewStyle().Foreground(m.theme.StatusError)
+	} else {
+		m.statusStyle = lipgloss.NewStyle().Foreground(m.theme.StatusOK)
+	}
+	return tea.Tick(time.Second*3, func(t time.Time) tea.Msg { return clearStatusMsg{} })
+}
+
+// pullRequestURL builds the compare URL for the current branch against the default branch
+func (m Model) pullRequestURL(open bool) tea.Cmd {
+	branch := m.dashboard.branch
+	defaultBranch := m.dashboard.defaultBranch
+	detached := m.dashboard.detached
+	return func() tea.Msg {
+		if detached {
+			return prURLMsg{err: fmt.Errorf("HEAD is detached, check out a branch first")}
+		}
+		if defaultBranch == "" {
+			return prURLMsg{err: fmt.Errorf("could not detect default branch")}
+		}
+		if branch == "" || branch == defaultBranch {
+			return prURLMsg{err: fmt.Errorf("already on the default branch")}
+		}
+		url, err := git.GetPullRequestURL(branch, defaultBranch)
+		return prURLMsg{url: url, open: open, err: err}
+	}
+}
+
+// canJumpToDefaultBranch reports whether H switches to the default branch
+// in the current view. Views prompting for text take it as input instead.
+func (m Model) canJumpToDefaultBranch() bool {
+	switch m.viewMode {
+	case viewDashboard, viewBranches:
+		return true
+	case viewGraph:
+		return m.graph != nil && !m.graph.typing()
+	case viewStaging:
+		return m.staging != nil && !m.staging.typing()
+	}
+	return false
+}
+
+// checkDefaultBranchSwitch looks up the default branch and whether the
+// working tree is dirty before switching to it
+func (m Model) checkDefaultBranchSwitch() tea.Cmd {
+	current := m.dashboard.branch
+	return func() tea.Msg {
+		target, err := git.GetDefaultBranch()
+		if err != nil {
+			return defaultBranchCheckMsg{err: err}
+		}
+		if target == current {
+			return defaultBranchCheckMsg{err: fmt.Errorf("already on %s", target)}
+		}
+		dirty, err := git.HasUncommittedChanges()
+		return defaultBranchCheckMsg{target: target, dirty: dirty, err: err}
+	}
+}
+
+// checkQuit looks up whether the working tree is dirty and a commit message
+// would be lost before quitting
+func (m Model) checkQuit(always bool) tea.Cmd {
+	draft := m.commitDraft != ""
+	if m.viewMode == viewCommitFlow && m.commitFlow != nil {
+		draft = m.commitFlow.Draft() != ""
+	}
+	return func() tea.Msg {
+		dirty, err := git.HasUncommittedChanges()
+		// Rather ask needlessly than quit on a working tree we couldn't check
+		if err != nil {
+			dirty = true
+		}
+		return quitCheckMsg{always: always, dirty: dirty, draft: draft}
+	}
+}
+
+// startSync looks up the current branch's upstream before pushing or pulling
+func (m Model) startSync(push bool) tea.Cmd {
+	branch := m.dashboard.branch
+	detached := m.dashboard.detached
+	return func() tea.Msg {
+		if detached {
+			return syncStartMsg{err: fmt.Errorf("HEAD is detached, check out a branch first")}
+		}
+		remote, remoteBranch, err := git.GetUpstream(branch)
+		msg := syncStartMsg{
+			push:         push,
+			branch:       branch,
+			remote:       remote,
+			remoteBranch: remoteBranch,
+			err:          err,
+		}
+		if push && remote == "" {
+			msg.first = git.DefaultRemote()
+		}
+		return msg
+	}
+}
+
+// pullCmd fetches from remote and fast-forwards to remoteBranch
+func pullCmd(remote, remoteBranch string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.Fetch(remote); err != nil {
+			return syncDoneMsg{err: err}
+		}
+		if err := git.Pull(remote, remoteBranch); err != nil {
+			return syncDoneMsg{err: err}
+		}
+		return syncDoneMsg{status: "Pulled " + remote + "/" + remoteBranch}
+	}
+}
+
+// pushCmd pushes branch to remoteBranch on remote, or sets remote/branch
+// as its upstream on a first push
+func pushCmd(remote, branch, remoteBranch string, setUpstream bool) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if setUpstream {
+			err = git.PushSetUpstream(remote, branch)
+		} else {
+			err = git.Push(remote, branch, remoteBranch, false)
+		}
+		if err != nil {
+			return syncDoneMsg{err: err}
+		}
+		return syncDoneMsg{status: "Pushed " + branch + " to " + remote + "/" + remoteBranch}
+	}
+}
+
+// branchAtCommitCmd creates branch name at commit without switching to it
+func branchAtCommitCmd(name, commit string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.ValidateBranchName(name); err != nil {
+			return branchAtCommitMsg{name: name, err: err}
+		}
+		short := commit
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		return branchAtCommitMsg{name: name, short: short, err: git.CreateBranchAt(name, commit)}
+	}
+}
+
+// moveChangesCmd stashes the working tree, switches to branch and reapplies it
+func moveChangesCmd(branch string) tea.Cmd {
+	return func() tea.Msg {
+		return changesMovedMsg{branch: branch, err: git.MoveChangesToBranch(branch)}
+	}
+}
+
+// confirmStashConflict reports a stash that applied with conflicts and asks
+// whether to undo it. Declining opens the staging view to resolve by hand.
+func (m *Model) confirmStashConflict(err error, index int) {
+	m.confirm = NewConfirmView(
+		fmt.Sprintf("Error: %v.\n\nUndo the partial pop and restore the working tree? Answer n to resolve the conflicts in the staging view instead.", err),
+		abortStashPopCmd(index),
+		func() tea.Msg { return resolveConflictsMsg{} },
+		m.theme,
+	)
+	m.confirm, _ = m.confirm.Update(m.windowSize())
+}
+
+// confirmConflicts reports an operation that stopped on conflicts and
+// offers to resolve them in the staging view. Declining leaves it in
+// progress, nextSteps says how to finish it from the dashboard.
+func (m *Model) confirmConflicts(summary, nextSteps string) {
+	m.confirm = NewConfirmView(
+		fmt.Sprintf("%s\n\nResolve them in the staging view now? Answer n to leave it in progress, then %s.", summary, nextSteps),
+		func() tea.Msg { return resolveConflictsMsg{} },
+		nil,
+		m.theme,
+	)
+	m.confirm, _ = m.confirm.Update(m.windowSize())
+}
+
+// editChangedFile opens the only changed file in the editor, reloading the
+// dashboard once it exits. With several changed files the staging view
+// opens instead, where e opens the selected one.
+func (m *Model) editChangedFile() tea.Cmd {
+	var paths []string
+	for _, file := range m.dashboard.files {
+		// Deleted files and collapsed directories have nothing to edit
+		if file.IsIgnored || file.IsDir() || file.Status == models.StatusDeleted {
+			continue
+		}
+		paths = append(paths, file.Path)
+	}
+
+	switch len(paths) {
+	case 0:
+		return m.setStatus("No changed files to open", false)
+	case 1:
+		path := filepath.Join(git.Current().Root, paths[0])
+		return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+			return fileEditedMsg{err}
+		})
+	}
+
+	m.staging = NewStagingView(m.cfg, m.theme)
+	m.staging, _ = m.staging.Update(m.windowSize())
+	m.viewMode = viewStaging
+	m.statusMsg = ""
+	return m.staging.Init()
+}
+
+// abortStashPopCmd undoes a conflicted stash pop, keeping the stash
+func abortStashPopCmd(index int) tea.Cmd {
+	return func() tea.Msg {
+		return stashPopAbortedMsg{index: index, err: git.AbortStashPop(index)}
+	}
+}
+
+// switchBranchCmd checks out a branch
+func switchBranchCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		return branchSwitchedMsg{name: name, err: git.SwitchBranch(name)}
+	}
+}
+
+// trackBranchCmd creates the branch local tracking remoteBranch and
+// switches to it
+func trackBranchCmd(local, remoteBranch string) tea.Cmd {
+	return func() tea.Msg {
+		return branchSwitchedMsg{name: local, err: git.CheckoutTracking(local, remoteBranch)}
+	}
+}
+
 func (m Model) View() string {
+	if m.confirm != nil {
+		return m.confirm.View()
+	}
+
 	switch m.viewMode {
 	case viewBranchInput:
 		if m.branchInput != nil {
@@ -175,12 +1328,52 @@ func (m Model) View() string {
 		if m.commitFlow != nil {
 			return m.commitFlow.View()
 		}
+	case viewMaintenance:
+		if m.maintenance != nil {
+			return m.maintenance.View()
+		}
+	case viewStash:
+		if m.stash != nil {
+			return m.stash.View()
+		}
+	case viewGraph:
+		if m.graph != nil {
+			return m.graph.View()
+		}
+	case viewBranches:
+		if m.branches != nil {
+			return m.branches.View()
+		}
+	case viewStaging:
+		if m.staging != nil {
+			return m.staging.View()
+		}
+	case viewReflog:
+		if m.reflog != nil {
+			return m.reflog.View()
+		}
+	case viewRemotes:
+		if m.remotes != nil {
+			return m.remotes.View()
+		}
+	case viewBranchDiff:
+		if m.branchDiff != nil {
+			return m.branchDiff.View()
+		}
+	case viewAbout:
+		if m.about != nil {
+			return m.about.View()
+		}
 	}
 
 	// Dashboard view with optional status message
 	view := m.dashboard.View()
 	if m.statusMsg != "" {
-		view += "\n" + m.statusStyle.Render(m.statusMsg)
+		status := m.statusStyle.Render(m.statusMsg)
+		if m.sync.loading {
+			status = m.sync.spinner.View() + " " + status
+		}
+		view += "\n" + status
 	}
 	return view
 }
@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+type branchDiffLoadedMsg struct {
+	base  string // e.g. "origin/main"
+	files []models.FileChange
+}
+
+// branchDiffPreviewMsg carries the diff of the file at path since base
+type branchDiffPreviewMsg struct {
+	path string
+	diff string
+	err  error
+}
+
+type branchDiffCloseMsg struct{}
+
+// BranchDiffView lists the files the current branch changed since it forked
+// from the default branch, with the diff of the selected one
+type BranchDiffView struct {
+	theme   *Theme
+	base    string
+	files   []models.FileChange
+	loaded  bool
+	loader  loader
+	cursor  int
+	keys    config.KeyMap
+	preview viewport.Model // diff of the selected file
+	err     error
+	width   int
+	height  int
+}
+
+func NewBranchDiffView(cfg *config.Config, theme *Theme) *BranchDiffView {
+	// j/k move through the list, so the preview only scrolls by page
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewport.KeyMap{
+		PageDown:     key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:       key.NewBinding(key.WithKeys("pgup")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+	}
+
+	return &BranchDiffView{
+		theme:   theme,
+		loader:  newLoader(theme),
+		cursor:  0,
+		keys:    cfg.Keys,
+		preview: vp,
+	}
+}
+
+func (b *BranchDiffView) Init() tea.Cmd {
+	return b.loadFiles()
+}
+
+func (b *BranchDiffView) loadFiles() tea.Cmd {
+	return b.loader.start(func() tea.Msg {
+		defaultBranch, err := git.GetDefaultBranch()
+		if err != nil {
+			return errMsg{err}
+		}
+		base := git.DefaultRemote() + "/" + defaultBranch
+		files, err := git.GetBranchDiffFiles(base)
+		if err != nil {
+			return errMsg{err}
+		}
+		return branchDiffLoadedMsg{base: base, files: files}
+	})
+}
+
+// loadPreview fetches the diff of the selected file
+func (b *BranchDiffView) loadPreview() tea.Cmd {
+	file := b.SelectedFile()
+	if file == nil {
+		b.preview.SetContent("")
+		return nil
+	}
+
+	base, path := b.base, file.Path
+	paths := []string{path}
+	if file.OldPath != "" {
+		paths = append(paths, file.OldPath)
+	}
+	return func() tea.Msg {
+		diff, err := git.GetBranchDiff(base, paths...)
+		return branchDiffPreviewMsg{path: path, diff: diff, err: err}
+	}
+}
+
+func (b *BranchDiffView) Update(msg tea.Msg) (*BranchDiffView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case branchDiffLoadedMsg:
+		b.base = msg.base
+		b.files = msg.files
+		b.loaded = true
+		b.loader.stop()
+		b.err = nil
+		if b.cursor >= len(b.files) {
+			b.cursor = len(b.files) - 1
+		}
+		if b.cursor < 0 {
+			b.cursor = 0
+		}
+		b.resizePreview()
+		return b, b.loadPreview()
+
+	case branchDiffPreviewMsg:
+		// Skip previews for a file that is no longer selected
+		if file := b.SelectedFile(); file == nil || file.Path != msg.path {
+			return b, nil
+		}
+		if msg.err != nil {
+			b.preview.SetContent(lipgloss.NewStyle().
+				Foreground(b.theme.StatusError).
+				Render(fmt.Sprintf("Error: %v", msg.err)))
+		} else {
+			b.preview.SetContent(colorizeDiff(b.theme, expandTabs(msg.diff), true))
+		}
+		b.preview.GotoTop()
+		return b, nil
+
+	case errMsg:
+		b.err = msg.err
+		b.loaded = true
+		b.loader.stop()
+
+	case spinner.TickMsg:
+		return b, b.loader.tick(msg)
+
+	case tea.KeyMsg:
+		switch key := msg.String(); {
+		case key == "esc":
+			return b, func() tea.Msg { return branchDiffCloseMsg{} }
+
+		case b.keys.Down.Matches(key):
+			if b.cursor < len(b.files)-1 {
+				b.cursor++
+				return b, b.loadPreview()
+			}
+
+		case b.keys.Up.Matches(key):
+			if b.cursor > 0 {
+				b.cursor--
+				return b, b.loadPreview()
+			}
+
+		case key == "r":
+			return b, b.loadFiles()
+
+		default:
+			// Page keys scroll the preview
+			var cmd tea.Cmd
+			b.preview, cmd = b.preview.Update(msg)
+			return b, cmd
+		}
+
+	case tea.WindowSizeMsg:
+		b.width = msg.Width
+		b.height = msg.Height
+		b.resizePreview()
+	}
+
+	return b, nil
+}
+
+// resizePreview gives the preview whatever height the list leaves free
+func (b *BranchDiffView) resizePreview() {
+	listHeight := len(b.files) + 5 // header, divider and help lines
+	if len(b.files) == 0 {
+		listHeight++
+	}
+
+	b.preview.Width = b.width
+	b.preview.Height = b.height - listHeight
+	if b.preview.Height < 5 {
+		b.preview.Height = 5
+	}
+}
+
+func (b *BranchDiffView) View() string {
+	grayStyle := lipgloss.NewStyle().Foreground(b.theme.Muted)
+
+	if !b.loaded {
+		return b.loader.view("Loading changes...")
+	}
+
+	errorStyle := lipgloss.NewStyle().Foreground(b.theme.StatusError)
+	if b.err != nil {
+		return errorStyle.Render(fmt.Sprintf("Error: %v", b.err)) + "\n\n" + grayStyle.Render("r: retry • esc: back")
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(b.theme.Accent).
+		Bold(true).
+		MarginBottom(1)
+
+	pathStyle := lipgloss.NewStyle().Foreground(b.theme.Text)
+	selectedStyle := lipgloss.NewStyle().Background(b.theme.Selection)
+	dividerStyle := lipgloss.NewStyle().Foreground(b.theme.Border)
+
+	var out strings.Builder
+
+	out.WriteString(headerStyle.Render(fmt.Sprintf("Changes since %s (%d)", b.base, len(b.files))+b.loader.indicator()) + "\n")
+
+	if len(b.files) == 0 {
+		out.WriteString(grayStyle.Render("  No changes since this branch forked from "+b.base) + "\n")
+	}
+
+	for i, file := range b.files {
+		line := b.statusStyle(file.Status).Render(string(file.Status)) + " "
+		if file.OldPath != "" {
+			line += grayStyle.Render(file.OldPath+" → ") + pathStyle.Render(file.Path)
+		} else {
+			line += pathStyle.Render(file.Path)
+		}
+
+		if i == b.cursor {
+			line = selectedStyle.Render("▸ " + line)
+		} else {
+			line = "  " + line
+		}
+
+		out.WriteString(line + "\n")
+	}
+
+	if len(b.files) > 0 {
+		out.WriteString(dividerStyle.Render(strings.Repeat("─", b.width)) + "\n")
+		out.WriteString(b.preview.View() + "\n")
+	}
+
+	out.WriteString("\n" + grayStyle.Render("pgup/pgdn: scroll diff • r: refresh • esc: back"))
+
+	return out.String()
+}
+
+// statusStyle colors a file's status letter the way the dashboard does
+func (b *BranchDiffView) statusStyle(status models.FileStatus) lipgloss.Style {
+	switch status {
+	case models.StatusAdded:
+		return lipgloss.NewStyle().Foreground(b.theme.Success).Bold(true)
+	case models.StatusDeleted:
+		return lipgloss.NewStyle().Foreground(b.theme.Danger).Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(b.theme.Text).Bold(true)
+}
+
+func (b *BranchDiffView) SelectedFile() *models.FileChange {
+	if b.cursor >= 0 && b.cursor < len(b.files) {
+		return &b.files[b.cursor]
+	}
+	return nil
+}
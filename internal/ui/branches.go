@@ -1,26 +1,41 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
 	"github.com/Johannes-Berggren/GitGoblin/internal/git"
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
 
 type BranchView struct {
+	theme        *Theme
 	branches     []models.Branch
-	localOnly    []models.Branch
+	listed       []models.Branch // local branches, plus untracked remote ones with showRemotes
+	showRemotes  bool
+	loader       loader
 	cursor       int
 	width        int
 	height       int
+	fullHashes   bool
+	keys         config.KeyMap
+	status       string
+	restore      string // branch to select once the list loads
+	err          error
 }
 
-func NewBranchView() *BranchView {
+func NewBranchView(cfg *config.Config, theme *Theme) *BranchView {
 	return &BranchView{
-		cursor: 0,
+		theme:      theme,
+		loader:     newLoader(theme),
+		cursor:     0,
+		fullHashes: cfg.FullHashes,
+		keys:       cfg.Keys,
 	}
 }
 
@@ -28,52 +43,261 @@ type branchesLoadedMsg struct {
 	branches []models.Branch
 }
 
+type branchViewCloseMsg struct{}
+
+// branchSwitchCheckMsg carries the dirty check done before switching branches
+type branchSwitchCheckMsg struct {
+	name  string
+	dirty bool
+	err   error
+}
+
+// branchMergedMsg reports the result of merging a branch into the current one
+type branchMergedMsg struct {
+	name string
+	err  error
+}
+
+type branchDeletedMsg struct {
+	name  string
+	force bool
+	err   error
+}
+
+// branchRenameRequestMsg asks the app to prompt for a new name for a branch
+type branchRenameRequestMsg struct {
+	name string
+}
+
+// branchRenamedMsg reports the result of renaming a branch. remote and
+// remoteBranch are the upstream it still tracks, empty without one.
+type branchRenamedMsg struct {
+	oldName      string
+	name         string
+	remote       string
+	remoteBranch string
+	err          error
+}
+
+// branchRepushedMsg reports the result of pushing a renamed branch under
+// its new name and tracking that
+type branchRepushedMsg struct {
+	name   string
+	remote string
+	err    error
+}
+
 func (b *BranchView) Init() tea.Cmd {
 	return b.loadBranches()
 }
 
 func (b *BranchView) loadBranches() tea.Cmd {
-	return func() tea.Msg {
+	return b.loader.start(func() tea.Msg {
 		branches, err := git.GetBranches()
 		if err != nil {
 			return errMsg{err}
 		}
 		return branchesLoadedMsg{branches}
-	}
+	})
 }
 
 func (b *BranchView) Update(msg tea.Msg) (*BranchView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case branchesLoadedMsg:
 		b.branches = msg.branches
-		// Filter to local branches only for display
-		b.localOnly = []models.Branch{}
-		for _, branch := range b.branches {
-			if !branch.IsRemote {
-				b.localOnly = append(b.localOnly, branch)
+		b.loader.stop()
+		b.filterBranches()
+		if b.restore != "" {
+			for i, branch := range b.listed {
+				if branch.Name == b.restore {
+					b.cursor = i
+					break
+				}
 			}
+			b.restore = ""
+		}
+		if b.cursor >= len(b.listed) {
+			b.cursor = len(b.listed) - 1
+		}
+		if b.cursor < 0 {
+			b.cursor = 0
+		}
+
+	case errMsg:
+		b.err = msg.err
+		b.loader.stop()
+
+	case spinner.TickMsg:
+		return b, b.loader.tick(msg)
+
+	case branchSwitchCheckMsg:
+		if msg.err != nil {
+			b.err = msg.err
+			return b, nil
+		}
+		if msg.dirty {
+			return b, requestConfirm(
+				fmt.Sprintf("You have uncommitted changes. Stash them and switch to %s? Pop them back later from the stash list.", msg.name),
+				stashAndSwitchCmd(msg.name),
+				nil,
+			)
+		}
+		return b, switchBranchCmd(msg.name)
+
+	case branchSwitchedMsg:
+		// The app only forwards failed switches, successful ones close the view
+		b.err = msg.err
+		b.status = ""
+
+	case branchMergedMsg:
+		// The app closes the view unless the merge failed outright
+		b.err = msg.err
+		b.status = ""
+
+	case branchDeletedMsg:
+		if errors.Is(msg.err, git.ErrBranchNotMerged) {
+			// Offer a force delete, the unmerged commits are only reachable via the reflog afterwards
+			return b, requestConfirm(
+				fmt.Sprintf("%s is not fully merged. Force delete it and lose its unmerged commits?", msg.name),
+				deleteBranchCmd(msg.name, true),
+				nil,
+			)
+		}
+		if msg.err != nil {
+			b.err = msg.err
+			b.status = ""
+			return b, nil
+		}
+		b.err = nil
+		b.status = "Deleted branch " + msg.name
+		return b, b.loadBranches()
+
+	case branchRenamedMsg:
+		if msg.err != nil {
+			b.err = msg.err
+			b.status = ""
+			return b, nil
+		}
+		b.err = nil
+		b.status = fmt.Sprintf("Renamed %s to %s", msg.oldName, msg.name)
+		if msg.remote == "" || msg.remoteBranch == msg.name {
+			return b, b.loadBranches()
+		}
+		// The upstream still points at the old name on the remote
+		prompt := fmt.Sprintf("%s still tracks %s/%s. Push it as %s/%s and track that instead? The old remote branch is left in place.",
+			msg.name, msg.remote, msg.remoteBranch, msg.remote, msg.name)
+		return b, tea.Batch(b.loadBranches(), requestConfirm(prompt, repushBranchCmd(msg.remote, msg.name), nil))
+
+	case branchRepushedMsg:
+		if msg.err != nil {
+			b.err = msg.err
+			b.status = ""
+			return b, nil
 		}
+		b.err = nil
+		b.status = fmt.Sprintf("Pushed %s to %s and set it as upstream", msg.name, msg.remote)
+		return b, b.loadBranches()
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "j", "down":
-			if b.cursor < len(b.localOnly)-1 {
+		switch key := msg.String(); {
+		case key == "esc":
+			return b, func() tea.Msg { return branchViewCloseMsg{} }
+
+		case b.keys.Down.Matches(key):
+			if b.cursor < len(b.listed)-1 {
 				b.cursor++
 			}
 
-		case "k", "up":
+		case b.keys.Up.Matches(key):
 			if b.cursor > 0 {
 				b.cursor--
 			}
 
-		case "g":
+		case b.keys.Top.Matches(key):
 			b.cursor = 0
 
-		case "G":
-			b.cursor = len(b.localOnly) - 1
+		case b.keys.Bottom.Matches(key):
+			b.cursor = len(b.listed) - 1
 
-		case "r":
+		case key == "r":
 			return b, b.loadBranches()
+
+		case key == "enter":
+			branch := b.SelectedBranch()
+			if branch == nil || branch.IsCurrent {
+				return b, nil
+			}
+			if branch.IsRemote {
+				// Check out a local branch tracking it, named after it
+				_, local, _ := strings.Cut(branch.Name, "/")
+				return b, requestConfirm(
+					fmt.Sprintf("Create %s tracking %s and switch to it?", local, branch.Name),
+					trackBranchCmd(local, branch.Name),
+					nil,
+				)
+			}
+			return b, checkBranchSwitchCmd(branch.Name)
+
+		case key == "A":
+			// Toggle listing remote branches no local branch tracks
+			name := ""
+			if branch := b.SelectedBranch(); branch != nil {
+				name = branch.Name
+			}
+			b.showRemotes = !b.showRemotes
+			b.filterBranches()
+			b.cursor = 0
+			for i, branch := range b.listed {
+				if branch.Name == name {
+					b.cursor = i
+					break
+				}
+			}
+
+		case key == "m" || key == "M":
+			// Merge the selected branch into the current one, M always
+			// creates a merge commit
+			branch := b.SelectedBranch()
+			if branch == nil || branch.IsCurrent {
+				return b, nil
+			}
+			noFF := key == "M"
+			prompt := fmt.Sprintf("Merge %s into the current branch?", branch.Name)
+			if noFF {
+				prompt = fmt.Sprintf("Merge %s into the current branch with a merge commit?", branch.Name)
+			}
+			return b, requestConfirm(prompt, mergeBranchCmd(branch.Name, noFF), nil)
+
+		case key == "#":
+			// Toggle full/short hashes
+			b.fullHashes = !b.fullHashes
+
+		case key == "R":
+			// Rename the selected branch
+			if branch := b.SelectedBranch(); branch != nil && !branch.IsRemote {
+				name := branch.Name
+				return b, func() tea.Msg { return branchRenameRequestMsg{name} }
+			}
+
+		case key == "d":
+			// Delete the selected branch after confirmation
+			branch := b.SelectedBranch()
+			if branch == nil {
+				return b, nil
+			}
+			if branch.IsCurrent {
+				b.err = fmt.Errorf("cannot delete the current branch")
+				return b, nil
+			}
+			if branch.IsRemote {
+				b.err = fmt.Errorf("cannot delete remote branch %s from here", branch.Name)
+				return b, nil
+			}
+			return b, requestConfirm(
+				fmt.Sprintf("Delete branch %s?", branch.Name),
+				deleteBranchCmd(branch.Name, false),
+				nil,
+			)
 		}
 
 	case tea.WindowSizeMsg:
@@ -84,58 +308,149 @@ func (b *BranchView) Update(msg tea.Msg) (*BranchView, tea.Cmd) {
 	return b, nil
 }
 
+// filterBranches lists the local branches, followed by the remote ones when
+// showRemotes is set. Remote branches a local branch already tracks are
+// left out, the local branch shows their status.
+func (b *BranchView) filterBranches() {
+	tracked := map[string]bool{}
+	for _, branch := range b.branches {
+		if !branch.IsRemote && branch.Upstream != "" {
+			tracked[branch.Upstream] = true
+		}
+	}
+
+	b.listed = []models.Branch{}
+	for _, branch := range b.branches {
+		if !branch.IsRemote {
+			b.listed = append(b.listed, branch)
+		}
+	}
+	if !b.showRemotes {
+		return
+	}
+	for _, branch := range b.branches {
+		if branch.IsRemote && !tracked[branch.Name] {
+			b.listed = append(b.listed, branch)
+		}
+	}
+}
+
+// syncSummary counts the local branches that need pushing, need pulling or
+// match their upstream, e.g. "2 to push • 1 behind • 3 in sync"
+func (b *BranchView) syncSummary() string {
+	var ahead, behind, synced int
+	for _, branch := range b.listed {
+		if branch.IsRemote || branch.Upstream == "" || branch.Gone {
+			continue
+		}
+		if branch.Ahead > 0 {
+			ahead++
+		}
+		if branch.Behind > 0 {
+			behind++
+		}
+		if branch.Ahead == 0 && branch.Behind == 0 {
+			synced++
+		}
+	}
+
+	var parts []string
+	if ahead > 0 {
+		parts = append(parts, fmt.Sprintf("%d to push", ahead))
+	}
+	if behind > 0 {
+		parts = append(parts, fmt.Sprintf("%d behind", behind))
+	}
+	if synced > 0 {
+		parts = append(parts, fmt.Sprintf("%d in sync", synced))
+	}
+	return strings.Join(parts, " • ")
+}
+
 func (b *BranchView) View() string {
-	if len(b.localOnly) == 0 {
+	if len(b.listed) == 0 && b.err != nil {
 		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Render("Loading branches...")
+			Foreground(b.theme.StatusError).
+			Render(fmt.Sprintf("Error: %v", b.err))
+	}
+
+	if len(b.listed) == 0 {
+		return b.loader.view("Loading branches...")
 	}
 
 	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(b.theme.Accent).
 		Bold(true).
 		MarginBottom(1)
 
 	branchStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("white"))
+		Foreground(b.theme.Text)
 
 	currentStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("green")).
+		Foreground(b.theme.Branch).
 		Bold(true)
 
+	remoteStyle := lipgloss.NewStyle().
+		Foreground(b.theme.Secondary)
+
+	dateStyle := lipgloss.NewStyle().
+		Foreground(b.theme.Muted)
+
 	hashStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("yellow"))
+		Foreground(b.theme.Highlight)
 
 	upstreamStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("244"))
+		Foreground(b.theme.Secondary)
 
 	selectedStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("238"))
+		Background(b.theme.Selection)
 
 	var out strings.Builder
 
-	header := fmt.Sprintf("Branches (%d local)", len(b.localOnly))
-	out.WriteString(headerStyle.Render(header) + "\n")
+	remotes := 0
+	for _, branch := range b.listed {
+		if branch.IsRemote {
+			remotes++
+		}
+	}
+	header := fmt.Sprintf("Branches (%d local)", len(b.listed)-remotes)
+	if b.showRemotes {
+		header = fmt.Sprintf("Branches (%d local, %d remote)", len(b.listed)-remotes, remotes)
+	}
+	if summary := b.syncSummary(); summary != "" {
+		header += " • " + summary
+	}
+	out.WriteString(headerStyle.Render(header+b.loader.indicator()) + "\n")
 
-	for i, branch := range b.localOnly {
+	for i, branch := range b.listed {
 		var line string
 
 		if branch.IsCurrent {
 			line = "* " + currentStyle.Render(branch.Name)
+		} else if branch.IsRemote {
+			line = "  " + remoteStyle.Render(branch.Name)
 		} else {
 			line = "  " + branchStyle.Render(branch.Name)
 		}
 
-		line += " " + hashStyle.Render(branch.Hash[:7])
+		hash := branch.Hash
+		if !b.fullHashes && len(hash) > 7 {
+			hash = hash[:7]
+		}
+		line += " " + hashStyle.Render(hash)
 
 		if branch.Upstream != "" {
-			line += " " + upstreamStyle.Render(fmt.Sprintf("[%s]", branch.Upstream))
+			line += " " + b.renderUpstream(branch)
 		}
 
 		if branch.LastCommit != "" {
 			line += " " + upstreamStyle.Render(branch.LastCommit)
 		}
 
+		if !branch.Date.IsZero() {
+			line += " " + dateStyle.Render("("+formatRelativeTime(branch.Date)+")")
+		}
+
 		if i == b.cursor {
 			line = selectedStyle.Render("▸ " + line)
 		} else {
@@ -145,12 +460,95 @@ func (b *BranchView) View() string {
 		out.WriteString(line + "\n")
 	}
 
+	if b.err != nil {
+		errorStyle := lipgloss.NewStyle().Foreground(b.theme.StatusError)
+		out.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %v", b.err)) + "\n")
+	} else if b.status != "" {
+		statusStyle := lipgloss.NewStyle().Foreground(b.theme.StatusOK)
+		out.WriteString("\n" + statusStyle.Render(b.status) + "\n")
+	}
+
 	return out.String()
 }
 
+// deleteBranchCmd deletes a local branch, forcing it only when asked to
+func deleteBranchCmd(name string, force bool) tea.Cmd {
+	return func() tea.Msg {
+		return branchDeletedMsg{name: name, force: force, err: git.DeleteBranch(name, force)}
+	}
+}
+
+// renameBranchCmd renames oldName to name, reporting the upstream it
+// tracks so a re-push can be offered
+func renameBranchCmd(oldName, name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := git.RenameBranch(oldName, name); err != nil {
+			return branchRenamedMsg{oldName: oldName, name: name, err: err}
+		}
+		remote, remoteBranch, _ := git.GetUpstream(name)
+		return branchRenamedMsg{oldName: oldName, name: name, remote: remote, remoteBranch: remoteBranch}
+	}
+}
+
+// repushBranchCmd pushes name to remote and tracks it as upstream
+func repushBranchCmd(remote, name string) tea.Cmd {
+	return func() tea.Msg {
+		return branchRepushedMsg{name: name, remote: remote, err: git.PushSetUpstream(remote, name)}
+	}
+}
+
+// mergeBranchCmd merges name into the current branch
+func mergeBranchCmd(name string, noFF bool) tea.Cmd {
+	return func() tea.Msg {
+		return branchMergedMsg{name: name, err: git.Merge(name, noFF)}
+	}
+}
+
+// checkBranchSwitchCmd checks for uncommitted changes before switching to name
+func checkBranchSwitchCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		dirty, err := git.HasUncommittedChanges()
+		return branchSwitchCheckMsg{name: name, dirty: dirty, err: err}
+	}
+}
+
+// stashAndSwitchCmd stashes uncommitted changes and switches to name
+func stashAndSwitchCmd(name string) tea.Cmd {
+	return func() tea.Msg {
+		return branchSwitchedMsg{name: name, stashed: true, err: git.StashAndSwitchBranch(name)}
+	}
+}
+
+// renderUpstream renders a branch's upstream with its ahead count in green
+// and behind count in orange, the way the dashboard shows them, e.g.
+// "[origin/main ↑2 ↓1]", or marks an upstream that no longer exists
+func (b *BranchView) renderUpstream(branch models.Branch) string {
+	upstreamStyle := lipgloss.NewStyle().Foreground(b.theme.Secondary)
+	aheadStyle := lipgloss.NewStyle().Foreground(b.theme.Success).Bold(true)
+	behindStyle := lipgloss.NewStyle().Foreground(b.theme.Warning).Bold(true)
+	goneStyle := lipgloss.NewStyle().Foreground(b.theme.Danger)
+
+	parts := []string{upstreamStyle.Render(branch.Upstream)}
+	if branch.Gone {
+		parts = append(parts, goneStyle.Render("✗ gone"))
+	}
+	if branch.Ahead > 0 {
+		parts = append(parts, aheadStyle.Render(fmt.Sprintf("↑%d", branch.Ahead)))
+	}
+	if branch.Behind > 0 {
+		parts = append(parts, behindStyle.Render(fmt.Sprintf("↓%d", branch.Behind)))
+	}
+	return upstreamStyle.Render("[") + strings.Join(parts, " ") + upstreamStyle.Render("]")
+}
+
+// Select selects the branch called name once the list loads
+func (b *BranchView) Select(name string) {
+	b.restore = name
+}
+
 func (b *BranchView) SelectedBranch() *models.Branch {
-	if b.cursor >= 0 && b.cursor < len(b.localOnly) {
-		return &b.localOnly[b.cursor]
+	if b.cursor >= 0 && b.cursor < len(b.listed) {
+		return &b.listed[b.cursor]
 	}
 	return nil
 }
@@ -7,18 +7,26 @@ import (
 )
 
 type branchInputDoneMsg struct {
-	name string
+	name   string
+	move   bool   // move uncommitted changes to an existing branch instead of creating one
+	rename string // branch to rename to name instead of creating one
+	at     string // commit to create the branch at instead of the default branch
 }
 
 type branchInputCancelMsg struct{}
 
 type BranchInputView struct {
+	theme     *Theme
 	textInput textinput.Model
+	move      bool
+	rename    string
+	at        string
+	atShort   string
 	width     int
 	height    int
 }
 
-func NewBranchInputView() *BranchInputView {
+func NewBranchInputView(theme *Theme) *BranchInputView {
 	ti := textinput.New()
 	ti.Placeholder = "feature/my-branch"
 	ti.Focus()
@@ -26,10 +34,39 @@ func NewBranchInputView() *BranchInputView {
 	ti.Width = 40
 
 	return &BranchInputView{
+		theme:     theme,
 		textInput: ti,
 	}
 }
 
+// NewMoveChangesInputView prompts for an existing branch to carry the
+// uncommitted changes over to
+func NewMoveChangesInputView(theme *Theme) *BranchInputView {
+	b := NewBranchInputView(theme)
+	b.textInput.Placeholder = "main"
+	b.move = true
+	return b
+}
+
+// NewRenameBranchInputView prompts for a new name for branch, starting
+// from its current one
+func NewRenameBranchInputView(branch string, theme *Theme) *BranchInputView {
+	b := NewBranchInputView(theme)
+	b.textInput.SetValue(branch)
+	b.textInput.CursorEnd()
+	b.rename = branch
+	return b
+}
+
+// NewBranchAtCommitInputView prompts for the name of a branch to create at
+// commit, short being its abbreviated hash
+func NewBranchAtCommitInputView(commit, short string, theme *Theme) *BranchInputView {
+	b := NewBranchInputView(theme)
+	b.at = commit
+	b.atShort = short
+	return b
+}
+
 func (b *BranchInputView) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -43,7 +80,8 @@ func (b *BranchInputView) Update(msg tea.Msg) (*BranchInputView, tea.Cmd) {
 		case "enter":
 			name := b.textInput.Value()
 			if name != "" {
-				return b, func() tea.Msg { return branchInputDoneMsg{name: name} }
+				move, rename, at := b.move, b.rename, b.at
+				return b, func() tea.Msg { return branchInputDoneMsg{name: name, move: move, rename: rename, at: at} }
 			}
 			return b, nil
 		case "esc":
@@ -61,11 +99,29 @@ func (b *BranchInputView) Update(msg tea.Msg) (*BranchInputView, tea.Cmd) {
 
 func (b *BranchInputView) View() string {
 	promptStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("12")).
+		Foreground(b.theme.Link).
 		Bold(true)
 
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
+		Foreground(b.theme.Muted)
+
+	if b.rename != "" {
+		return "\n" +
+			promptStyle.Render("Rename "+b.rename+" to: ") + b.textInput.View() + "\n\n" +
+			helpStyle.Render("enter to rename • esc to cancel")
+	}
+
+	if b.at != "" {
+		return "\n" +
+			promptStyle.Render("New branch at "+b.atShort+": ") + b.textInput.View() + "\n\n" +
+			helpStyle.Render("enter to create • esc to cancel")
+	}
+
+	if b.move {
+		return "\n" +
+			promptStyle.Render("Move changes to branch: ") + b.textInput.View() + "\n\n" +
+			helpStyle.Render("enter to stash, switch and reapply • esc to cancel")
+	}
 
 	return "\n" +
 		promptStyle.Render("New branch name: ") + b.textInput.View() + "\n\n" +
@@ -11,6 +11,7 @@ import (
 )
 
 type CommitView struct {
+	theme        *Theme
 	textarea     textarea.Model
 	width        int
 	height       int
@@ -18,7 +19,7 @@ type CommitView struct {
 	err          error
 }
 
-func NewCommitView(stagedCount int) *CommitView {
+func NewCommitView(stagedCount int, theme *Theme) *CommitView {
 	ta := textarea.New()
 	ta.Placeholder = "Commit message..."
 	ta.Focus()
@@ -27,6 +28,7 @@ func NewCommitView(stagedCount int) *CommitView {
 	ta.SetHeight(5)
 
 	return &CommitView{
+		theme:       theme,
 		textarea:    ta,
 		stagedCount: stagedCount,
 	}
@@ -79,7 +81,7 @@ func (c *CommitView) Update(msg tea.Msg) (*CommitView, tea.Cmd) {
 
 func (c *CommitView) performCommit(message string) tea.Cmd {
 	return func() tea.Msg {
-		err := git.Commit(message)
+		err := git.Commit(message, git.CommitOptions{})
 		if err != nil {
 			return errMsg{err}
 		}
@@ -89,15 +91,15 @@ func (c *CommitView) performCommit(message string) tea.Cmd {
 
 func (c *CommitView) View() string {
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(c.theme.Accent).
 		Bold(true).
 		MarginBottom(1)
 
 	errorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("red"))
+		Foreground(c.theme.Danger)
 
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
+		Foreground(c.theme.Muted).
 		MarginTop(1)
 
 	var b strings.Builder
@@ -0,0 +1,407 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+type commitDetailLoadedMsg struct {
+	hash    string // as requested, to drop a load that was navigated away from
+	detail  models.CommitDetail
+	parents []string // subject lines of detail.Parents, in order
+	head    bool     // the commit is HEAD, so its author can be amended
+	ident   string   // configured "Name <email>", offered as the new author
+}
+
+type commitDetailCloseMsg struct{}
+
+// authorAmendedMsg reports the result of rewriting the author of HEAD
+type authorAmendedMsg struct {
+	author string
+	err    error
+}
+
+// fixupCommittedMsg reports the result of committing the staged changes as a
+// fixup of the commit shown
+type fixupCommittedMsg struct {
+	hash string
+	err  error
+}
+
+// CommitDetailView shows a single commit's message, changed files and diff
+// in a scrollable pane
+type CommitDetailView struct {
+	theme      *Theme
+	hash       string
+	back       []string // commits navigated away from to a parent, esc returns to them
+	detail     *models.CommitDetail
+	parents    []string
+	loader     loader
+	viewport   viewport.Model
+	fullHashes bool
+	sign       bool // sign_commits, on top of git's commit.gpgsign
+	keys       config.KeyMap
+	wordDiff   bool // highlight changed words within modified lines
+	head       bool
+	ident      string
+	editing    bool // prompting for a new author
+	author     textinput.Model
+	authorErr  error
+	err        error
+	width      int
+	height     int
+}
+
+func NewCommitDetailView(hash string, fullHashes, sign bool, keys config.KeyMap, theme *Theme) *CommitDetailView {
+	vp := viewport.New(0, 0)
+	vp.KeyMap.Up = key.NewBinding(key.WithKeys(keys.Up...))
+	vp.KeyMap.Down = key.NewBinding(key.WithKeys(keys.Down...))
+
+	ti := textinput.New()
+	ti.Prompt = "Author: "
+	ti.Placeholder = "Name <email>"
+	ti.CharLimit = 200
+	ti.Width = 60
+
+	return &CommitDetailView{
+		theme:      theme,
+		loader:     newLoader(theme),
+		hash:       hash,
+		viewport:   vp,
+		author:     ti,
+		fullHashes: fullHashes,
+		sign:       sign,
+		keys:       keys,
+		wordDiff:   true,
+	}
+}
+
+func (c *CommitDetailView) Init() tea.Cmd {
+	hash := c.hash
+	return c.loader.start(func() tea.Msg {
+		detail, err := git.GetCommitDetail(hash)
+		if err != nil {
+			return errMsg{err}
+		}
+		msg := commitDetailLoadedMsg{hash: hash, detail: detail}
+		for _, parent := range detail.Parents {
+			subject, _ := git.GetCommitSubject(parent)
+			msg.parents = append(msg.parents, subject)
+		}
+		if head, err := git.GetLastCommit(); err == nil && head.Hash == detail.Hash {
+			msg.head = true
+			msg.ident, _ = git.GetAuthorIdent()
+		}
+		return msg
+	})
+}
+
+func (c *CommitDetailView) Update(msg tea.Msg) (*CommitDetailView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case commitDetailLoadedMsg:
+		if msg.hash != c.hash {
+			return c, nil
+		}
+		c.loader.stop()
+		c.detail = &msg.detail
+		c.parents = msg.parents
+		c.head = msg.head
+		c.ident = msg.ident
+		c.viewport.SetContent(c.renderContent())
+		c.viewport.GotoTop()
+		return c, nil
+
+	case errMsg:
+		c.err = msg.err
+		c.loader.stop()
+		return c, nil
+
+	case spinner.TickMsg:
+		return c, c.loader.tick(msg)
+
+	case tea.KeyMsg:
+		if c.editing {
+			return c, c.updateAuthorInput(msg)
+		}
+		switch key := msg.String(); {
+		case key == "esc" && len(c.back) > 0:
+			// Return to the commit whose parent this is
+			hash := c.back[len(c.back)-1]
+			c.back = c.back[:len(c.back)-1]
+			return c, c.open(hash)
+		case key == "esc" || key == "q":
+			return c, func() tea.Msg { return commitDetailCloseMsg{} }
+		case len(key) == 1 && key >= "1" && key <= "9" && c.detail != nil:
+			// Open the numbered parent, e.g. 2 for the merged side of a merge
+			if n := int(key[0] - '1'); n < len(c.detail.Parents) {
+				c.back = append(c.back, c.hash)
+				return c, c.open(c.detail.Parents[n])
+			}
+			return c, nil
+		case c.keys.Top.Matches(key):
+			c.viewport.GotoTop()
+			return c, nil
+		case c.keys.Bottom.Matches(key):
+			c.viewport.GotoBottom()
+			return c, nil
+		case key == "w":
+			// Toggle word-level highlighting, large diffs render faster without it
+			c.wordDiff = !c.wordDiff
+			if c.detail != nil {
+				c.viewport.SetContent(c.renderContent())
+			}
+			return c, nil
+		case key == "f" && c.detail != nil:
+			// Attach the staged changes to this commit, to squash in later
+			hash, short := c.detail.Hash, c.detail.ShortHash
+			prompt := fmt.Sprintf("Commit the staged changes as a fixup of %s %q?", short, c.detail.Message)
+			return c, requestConfirm(prompt, fixupCommit(hash, short, c.sign), nil)
+		case key == "a" && c.head:
+			// Rewrite the author of HEAD, defaulting to the configured one
+			ident := c.ident
+			if ident == "" {
+				ident = fmt.Sprintf("%s <%s>", c.detail.Author, c.detail.Email)
+			}
+			c.editing = true
+			c.authorErr = nil
+			c.author.SetValue(ident)
+			c.author.CursorEnd()
+			return c, c.author.Focus()
+		}
+
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		c.viewport.Width = msg.Width
+		c.viewport.Height = msg.Height - 2 // Leave room for the help line
+		if c.detail != nil {
+			c.viewport.SetContent(c.renderContent())
+		}
+		return c, nil
+	}
+
+	// Up/down keys, pgup/pgdn, ctrl+u/ctrl+d scroll the viewport
+	var cmd tea.Cmd
+	c.viewport, cmd = c.viewport.Update(msg)
+	return c, cmd
+}
+
+func (c *CommitDetailView) View() string {
+	grayStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+
+	if c.err != nil {
+		errorStyle := lipgloss.NewStyle().Foreground(c.theme.StatusError)
+		return errorStyle.Render(fmt.Sprintf("Error: %v", c.err)) + "\n\n" + grayStyle.Render("esc: back")
+	}
+	if c.detail == nil {
+		return c.loader.view("Loading commit...")
+	}
+
+	if c.editing {
+		errorStyle := lipgloss.NewStyle().Foreground(c.theme.StatusError)
+		view := c.viewport.View() + "\n" + c.author.View()
+		if c.authorErr != nil {
+			view += "  " + errorStyle.Render(c.authorErr.Error())
+		}
+		return view
+	}
+
+	extra := ""
+	switch n := len(c.detail.Parents); {
+	case n == 1:
+		extra += " • 1: parent"
+	case n > 1:
+		extra += fmt.Sprintf(" • 1-%d: parents", min(n, 9))
+	}
+	extra += " • f: fixup"
+	if c.head {
+		extra += " • a: amend author"
+	}
+	help := fmt.Sprintf("%s/%s: scroll • %s/%s: top/bottom • w: word diff%s • esc: back • %d%%",
+		c.keys.Down.Help(), c.keys.Up.Help(), c.keys.Top.Help(), c.keys.Bottom.Help(),
+		extra, int(c.viewport.ScrollPercent()*100))
+	return c.viewport.View() + "\n" + grayStyle.Render(help)
+}
+
+// open loads another commit in place of the current one
+func (c *CommitDetailView) open(hash string) tea.Cmd {
+	c.hash = hash
+	c.detail = nil
+	c.parents = nil
+	c.head = false
+	c.err = nil
+	return c.Init()
+}
+
+// updateAuthorInput handles keys while prompting for the new author of HEAD
+func (c *CommitDetailView) updateAuthorInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		c.editing = false
+		c.author.Blur()
+		return nil
+	case "enter":
+		name, email, err := parseAuthor(c.author.Value())
+		if err != nil {
+			c.authorErr = err
+			return nil
+		}
+		c.editing = false
+		c.author.Blur()
+		return amendAuthor(name, email, c.sign)
+	}
+
+	var cmd tea.Cmd
+	c.author, cmd = c.author.Update(msg)
+	return cmd
+}
+
+// parseAuthor splits "Name <email>" into its parts
+func parseAuthor(value string) (name, email string, err error) {
+	value = strings.TrimSpace(value)
+	open := strings.LastIndex(value, "<")
+	if open == -1 || !strings.HasSuffix(value, ">") {
+		return "", "", fmt.Errorf("expected Name <email>")
+	}
+	name = strings.TrimSpace(value[:open])
+	email = strings.TrimSpace(value[open+1 : len(value)-1])
+	if name == "" || email == "" {
+		return "", "", fmt.Errorf("expected Name <email>")
+	}
+	return name, email, nil
+}
+
+// amendAuthor rewrites the author of HEAD, in the foreground when the
+// rewritten commit gets signed so a passphrase prompt can take over the
+// terminal
+func amendAuthor(name, email string, sign bool) tea.Cmd {
+	author := fmt.Sprintf("%s <%s>", name, email)
+	amend := func() error { return git.AmendAuthor(name, email, git.CommitOptions{Sign: sign}) }
+	if sign || git.SignsCommits() {
+		return tea.Exec(foregroundFunc(amend), func(err error) tea.Msg {
+			return authorAmendedMsg{author: author, err: err}
+		})
+	}
+	return func() tea.Msg { return authorAmendedMsg{author: author, err: amend()} }
+}
+
+// fixupCommit commits the staged changes as a fixup of hash, in the
+// foreground when the commit gets signed the way amendAuthor does
+func fixupCommit(hash, short string, sign bool) tea.Cmd {
+	fixup := func() error { return git.CommitFixup(hash, git.CommitOptions{Sign: sign}) }
+	if sign || git.SignsCommits() {
+		return tea.Exec(foregroundFunc(fixup), func(err error) tea.Msg {
+			return fixupCommittedMsg{hash: short, err: err}
+		})
+	}
+	return func() tea.Msg { return fixupCommittedMsg{hash: short, err: fixup()} }
+}
+
+// renderContent renders the header, message, file stats and diff that the
+// viewport scrolls through
+func (c *CommitDetailView) renderContent() string {
+	labelStyle := lipgloss.NewStyle().Foreground(c.theme.Accent)
+	hashStyle := lipgloss.NewStyle().Foreground(c.theme.Highlight).Bold(true)
+	refStyle := lipgloss.NewStyle().Foreground(c.theme.Branch).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(c.theme.Text)
+	dateStyle := lipgloss.NewStyle().Foreground(c.theme.Secondary)
+	addStyle := lipgloss.NewStyle().Foreground(c.theme.Success)
+	delStyle := lipgloss.NewStyle().Foreground(c.theme.Danger)
+	grayStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+
+	d := c.detail
+	var b strings.Builder
+
+	hash := d.Hash
+	if !c.fullHashes {
+		hash = d.ShortHash
+	}
+	header := hashStyle.Render(hash)
+	if len(d.Refs) > 0 {
+		header += " " + refStyle.Render("("+strings.Join(d.Refs, ", ")+")")
+	}
+	b.WriteString(header + "\n")
+
+	b.WriteString(labelStyle.Render("Author:    ") + valueStyle.Render(fmt.Sprintf("%s <%s>", d.Author, d.Email)) +
+		" " + dateStyle.Render(d.Date.Format("2006-01-02 15:04")+" ("+formatRelativeTime(d.Date)+")") + "\n")
+	if d.CommitterDiffers() || !d.CommitDate.Equal(d.Date) {
+		b.WriteString(labelStyle.Render("Committer: ") + valueStyle.Render(fmt.Sprintf("%s <%s>", d.Committer, d.CommitterEmail)) +
+			" " + dateStyle.Render(d.CommitDate.Format("2006-01-02 15:04")+" ("+formatRelativeTime(d.CommitDate)+")") + "\n")
+	}
+
+	// Parents, numbered by the key that opens them
+	for i, parent := range d.Parents {
+		label := "           "
+		if i == 0 {
+			label = "Parent:    "
+			if len(d.Parents) > 1 {
+				label = "Parents:   "
+			}
+		}
+		if !c.fullHashes && len(parent) > 7 {
+			parent = parent[:7]
+		}
+		line := labelStyle.Render(label) + grayStyle.Render(fmt.Sprintf("%d ", i+1)) + hashStyle.UnsetBold().Render(parent)
+		if i < len(c.parents) && c.parents[i] != "" {
+			line += " " + valueStyle.Render(c.parents[i])
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+
+	// Full message, indented like git log
+	if strings.TrimSpace(d.Body) == "" {
+		b.WriteString("    " + grayStyle.Italic(true).Render("(no message)") + "\n")
+	} else {
+		for _, line := range strings.Split(d.Body, "\n") {
+			b.WriteString("    " + valueStyle.Render(line) + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	// Changed files with per-file line counts, in a path column and a
+	// right-aligned stat column
+	totalAdded, totalDeleted := 0, 0
+	pathWidth, addWidth, delWidth := 0, 1, 1
+	for _, f := range d.Files {
+		pathWidth = max(pathWidth, lipgloss.Width(f.Path))
+		addWidth = max(addWidth, len(fmt.Sprint(f.Added)))
+		delWidth = max(delWidth, len(fmt.Sprint(f.Deleted)))
+	}
+	statWidth := addWidth + delWidth + 3 // "+" and "-" signs and a space
+	if c.width > 0 {
+		// Long paths push their stat along rather than every stat off screen
+		pathWidth = min(pathWidth, max(c.width-statWidth-6, 20))
+	}
+	for _, f := range d.Files {
+		stat := grayStyle.Render(fmt.Sprintf("%*s", statWidth, "Bin"))
+		if !f.Binary {
+			stat = addStyle.Render(fmt.Sprintf("+%*d", addWidth, f.Added)) + " " + delStyle.Render(fmt.Sprintf("-%*d", delWidth, f.Deleted))
+			totalAdded += f.Added
+			totalDeleted += f.Deleted
+		}
+		padding := strings.Repeat(" ", max(pathWidth-lipgloss.Width(f.Path), 0))
+		b.WriteString(fmt.Sprintf("  %s%s  %s\n", valueStyle.Render(f.Path), padding, stat))
+	}
+	if len(d.Files) > 0 {
+		b.WriteString(grayStyle.Render(fmt.Sprintf("  %d files changed, ", len(d.Files))) +
+			addStyle.Render(fmt.Sprintf("+%d", totalAdded)) + grayStyle.Render(" ") +
+			delStyle.Render(fmt.Sprintf("-%d", totalDeleted)) + "\n")
+	}
+
+	if d.Diff != "" {
+		b.WriteString("\n" + colorizeDiff(c.theme, d.Diff, c.wordDiff) + "\n")
+	}
+
+	return b.String()
+}
@@ -1,12 +1,19 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
 	"github.com/Johannes-Berggren/GitGoblin/internal/git"
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
@@ -15,45 +22,245 @@ type commitFlowPanel int
 
 const (
 	panelStaging commitFlowPanel = iota
-	panelCommit
+	panelSubject
+	panelBody
 )
 
+// trailerPickerState tracks the ctrl+t trailer picker
+type trailerPickerState int
+
+const (
+	trailerPickerClosed trailerPickerState = iota
+	trailerPickerKey                       // choosing a trailer key
+	trailerPickerValue                     // typing the trailer value
+)
+
+// typePickerState tracks the ctrl+y Conventional Commits type picker
+type typePickerState int
+
+const (
+	typePickerClosed typePickerState = iota
+	typePickerType                   // choosing a commit type
+	typePickerScope                  // typing the optional scope
+)
+
+// maxSubjectLength is the subject length past which the commit panel warns
+const maxSubjectLength = 72
+
+// conventionalPrefix matches a subject's "type(scope)!: " prefix
+var conventionalPrefix = regexp.MustCompile(`^\w+(\([^)]*\))?(!)?: *`)
+
 type commitFlowDoneMsg struct {
 	message string
+	amended bool
+}
+
+type lastCommitMessageMsg struct {
+	message string
+	err     error
+}
+
+// commitTemplateMsg carries the commit.template contents, empty if unset
+type commitTemplateMsg struct {
+	template string
+	err      error
 }
 
-type commitFlowCancelMsg struct{}
+// commitFlowCancelMsg closes the commit flow, handing back the unfinished
+// message so reopening it can pick up where the user left off
+// protectedBranchMsg reports whether the current branch is protected
+type protectedBranchMsg struct {
+	branch    string
+	protected bool
+}
+
+type commitFlowCancelMsg struct {
+	draft string
+}
 
 type commitFlowFilesMsg struct {
 	files []models.FileChange
 }
 
+// summaryToggledMsg reports a file unstaged or restaged from the summary.
+// patch holds the staged changes of a partially staged file that was
+// unstaged, to put back when it is restaged.
+type summaryToggledMsg struct {
+	path  string
+	patch string
+	files []models.FileChange
+}
+
 type CommitFlowView struct {
-	files      []models.FileChange
-	cursor     int
-	panel      commitFlowPanel
-	textarea   textarea.Model
-	width      int
-	height     int
-	err        error
+	theme         *Theme
+	files         []models.FileChange
+	cursor        int
+	hunkPath      string // file whose hunks are open for staging, empty when closed
+	hunks         hunkList
+	panel         commitFlowPanel
+	subjectInput  textinput.Model
+	body          textarea.Model
+	trailerKeys   []string
+	trailers      []models.Trailer
+	trailerPicker trailerPickerState
+	trailerCursor int
+	trailerInput  textinput.Model
+	commitTypes   []string
+	typePicker    typePickerState
+	typeCursor    int
+	scopeInput    textinput.Model
+	keys          config.KeyMap
+	cfg           *config.Config
+	branch        string
+	protected     bool           // branch is protected, committing needs confirmation
+	offerAll      bool           // offer git commit -a when nothing is staged
+	promptAll     bool           // showing the commit -a prompt
+	commitAll     bool           // user accepted committing all tracked changes
+	amend         bool           // amend HEAD instead of creating a new commit
+	allowEmpty    bool           // commit even when nothing is staged
+	noVerify      bool           // skip the pre-commit and commit-msg hooks
+	hookErr       *git.HookError // last commit rejected by a hook, nil otherwise
+	hookOutput    viewport.Model // what the rejecting hook printed
+	amendMessage  string
+	draft         string   // message typed before switching to amend mode
+	summary       bool     // reviewing staged files before committing
+	summaryFiles  []string // paths that were staged when the summary opened
+	summaryCursor int
+	summaryMsg    string
+	savedPatches  map[string]string // see summaryToggledMsg
+	loaded        bool
+	width         int
+	height        int
+	err           error
 }
 
-func NewCommitFlowView() *CommitFlowView {
+func NewCommitFlowView(cfg *config.Config, theme *Theme) *CommitFlowView {
+	subject := textinput.New()
+	subject.Placeholder = "Subject"
+	subject.Width = 60
+
 	ta := textarea.New()
-	ta.Placeholder = "Commit message..."
+	ta.Placeholder = "Body (optional)"
 	ta.CharLimit = 0
 	ta.SetWidth(60)
-	ta.SetHeight(3)
+	ta.SetHeight(5)
+
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = 50
+
+	si := textinput.New()
+	si.Prompt = "Scope: "
+	si.Placeholder = "optional, enter to skip"
+	si.CharLimit = 50
+	si.Width = 50
+
+	// Only scroll by page so the hook output doesn't steal the arrow keys
+	vp := viewport.New(0, hookOutputLines)
+	vp.KeyMap = viewport.KeyMap{
+		PageDown:     key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:       key.NewBinding(key.WithKeys("pgup")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+	}
 
 	return &CommitFlowView{
-		cursor:   0,
-		panel:    panelStaging,
-		textarea: ta,
+		theme:        theme,
+		hookOutput:   vp,
+		cursor:       0,
+		panel:        panelStaging,
+		subjectInput: subject,
+		body:         ta,
+		trailerKeys:  cfg.CommitTrailers,
+		trailerInput: ti,
+		commitTypes:  cfg.CommitTypes,
+		scopeInput:   si,
+		keys:         cfg.Keys,
+		cfg:          cfg,
+		offerAll:     cfg.OfferCommitAll,
 	}
 }
 
 func (c *CommitFlowView) Init() tea.Cmd {
-	return c.loadFiles()
+	return tea.Batch(c.loadFiles(), loadCommitTemplate, c.checkProtected())
+}
+
+// checkProtected looks up whether the current branch is protected
+func (c *CommitFlowView) checkProtected() tea.Cmd {
+	cfg := c.cfg
+	return func() tea.Msg {
+		branch, err := git.GetCurrentBranch()
+		if err != nil || branch == "" {
+			return protectedBranchMsg{}
+		}
+		// Without a default branch only the configured patterns apply
+		defaultBranch, _ := git.GetDefaultBranch()
+		return protectedBranchMsg{branch: branch, protected: cfg.IsProtected(branch, defaultBranch)}
+	}
+}
+
+// Draft returns the message typed for a new commit, empty if it is blank.
+// While amending that is the message set aside when amend mode started.
+func (c *CommitFlowView) Draft() string {
+	draft := c.message()
+	if c.amend {
+		draft = c.draft
+	}
+	if strings.TrimSpace(draft) == "" {
+		return ""
+	}
+	return draft
+}
+
+// RestoreDraft pre-fills the message with a draft kept from an earlier
+// visit, which also keeps the commit template from replacing it
+func (c *CommitFlowView) RestoreDraft(draft string) {
+	if draft == "" {
+		return
+	}
+	c.setMessage(draft)
+}
+
+// message joins the subject and body with the blank line git expects
+func (c *CommitFlowView) message() string {
+	subject := strings.TrimSpace(c.subjectInput.Value())
+	body := strings.Trim(c.body.Value(), "\n ")
+	if body == "" {
+		return subject
+	}
+	return subject + "\n\n" + body
+}
+
+// setMessage splits a message into the subject and body fields
+func (c *CommitFlowView) setMessage(message string) {
+	subject, body, _ := strings.Cut(message, "\n")
+	c.subjectInput.SetValue(strings.TrimSuffix(subject, "\r"))
+	c.subjectInput.CursorEnd()
+	c.body.SetValue(strings.TrimLeft(body, "\r\n"))
+}
+
+// focus moves the keyboard focus to a panel
+func (c *CommitFlowView) focus(panel commitFlowPanel) tea.Cmd {
+	c.panel = panel
+	c.blurMessage()
+	switch panel {
+	case panelSubject:
+		return c.subjectInput.Focus()
+	case panelBody:
+		return c.body.Focus()
+	}
+	return nil
+}
+
+// blurMessage takes the focus off both message fields
+func (c *CommitFlowView) blurMessage() {
+	c.subjectInput.Blur()
+	c.body.Blur()
+}
+
+func loadCommitTemplate() tea.Msg {
+	template, err := git.GetCommitTemplate()
+	return commitTemplateMsg{template: template, err: err}
 }
 
 func (c *CommitFlowView) loadFiles() tea.Cmd {
@@ -70,8 +277,28 @@ func (c *CommitFlowView) Update(msg tea.Msg) (*CommitFlowView, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case summaryToggledMsg:
+		if msg.patch != "" {
+			c.savedPatches[msg.path] = msg.patch
+		} else {
+			delete(c.savedPatches, msg.path)
+		}
+		return c.Update(commitFlowFilesMsg{msg.files})
+
 	case commitFlowFilesMsg:
+		// Keep the cursor on the same file when the list shifts
+		selected := ""
+		if c.cursor >= 0 && c.cursor < len(c.files) {
+			selected = c.files[c.cursor].Path
+		}
 		c.files = msg.files
+		if i := indexOfPath(c.files, selected); i >= 0 {
+			c.cursor = i
+		}
+		if !c.loaded {
+			c.loaded = true
+			c.promptAll = c.offerAll && !c.hasStagedFiles() && c.hasTrackedChanges()
+		}
 		// Keep cursor in bounds
 		if c.cursor >= len(c.files) {
 			c.cursor = len(c.files) - 1
@@ -79,84 +306,359 @@ func (c *CommitFlowView) Update(msg tea.Msg) (*CommitFlowView, tea.Cmd) {
 		if c.cursor < 0 {
 			c.cursor = 0
 		}
+		if c.hunkPath != "" {
+			// Refresh the open hunks, the file may have been staged whole meanwhile
+			i := indexOfPath(c.files, c.hunkPath)
+			if i < 0 {
+				c.hunkPath = ""
+				return c, nil
+			}
+			file := c.files[i]
+			return c, func() tea.Msg { return loadFileDiff(file) }
+		}
+		return c, nil
+
+	case diffLoadedMsg:
+		if c.hunkPath == "" {
+			return c, nil
+		}
+		c.hunks.load(msg)
+		if len(c.hunks.items) == 0 {
+			c.err = fmt.Errorf("%s has no hunks to stage, stage it as a whole", c.hunkPath)
+			c.hunkPath = ""
+		}
+		return c, nil
+
+	case protectedBranchMsg:
+		c.branch = msg.branch
+		c.protected = msg.protected
+		return c, nil
+
+	case commitTemplateMsg:
+		if msg.err != nil {
+			c.err = msg.err
+			return c, nil
+		}
+		// Don't clobber anything typed while the template was loading
+		if msg.template != "" && c.message() == "" && !c.amend {
+			c.setMessage(msg.template)
+		}
 		return c, nil
 
+	case lastCommitMessageMsg:
+		if msg.err != nil {
+			c.err = msg.err
+			return c, nil
+		}
+		c.amendMessage = msg.message
+		c.draft = c.message()
+		c.amend = true
+		c.commitAll = false
+		c.promptAll = false
+		c.err = nil
+		c.setMessage(msg.message)
+		return c, c.focus(panelSubject)
+
 	case tea.KeyMsg:
+		if c.promptAll {
+			switch msg.String() {
+			case "y":
+				c.commitAll = true
+				c.promptAll = false
+				return c, c.focus(panelSubject)
+			case "n", "esc":
+				c.promptAll = false
+			}
+			return c, nil
+		}
+
+		if c.trailerPicker != trailerPickerClosed {
+			return c, c.updateTrailerPicker(msg)
+		}
+
+		if c.typePicker != typePickerClosed {
+			return c, c.updateTypePicker(msg)
+		}
+
+		if c.hookErr != nil {
+			if cmd, handled := c.updateHookOutput(msg); handled {
+				return c, cmd
+			}
+		}
+
+		if c.summary {
+			return c, c.updateSummary(msg)
+		}
+
+		if c.hunkPath != "" {
+			return c, c.updateHunks(msg)
+		}
+
 		switch msg.String() {
 		case "esc":
-			return c, func() tea.Msg { return commitFlowCancelMsg{} }
+			draft := c.Draft()
+			return c, func() tea.Msg { return commitFlowCancelMsg{draft: draft} }
 
-		case "tab":
-			// Toggle between panels
-			if c.panel == panelStaging {
-				c.panel = panelCommit
-				c.textarea.Focus()
-			} else {
-				c.panel = panelStaging
-				c.textarea.Blur()
+		case "ctrl+t":
+			// Open the trailer picker
+			if len(c.trailerKeys) > 0 {
+				c.trailerPicker = trailerPickerKey
+				c.trailerCursor = 0
+				c.blurMessage()
 			}
 			return c, nil
 
+		case "ctrl+y":
+			// Open the commit type picker
+			if len(c.commitTypes) > 0 {
+				c.typePicker = typePickerType
+				c.typeCursor = 0
+				c.blurMessage()
+			}
+			return c, nil
+
+		case "tab":
+			// Cycle subject → body → staging
+			switch c.panel {
+			case panelStaging:
+				return c, c.focus(panelSubject)
+			case panelSubject:
+				return c, c.focus(panelBody)
+			default:
+				return c, c.focus(panelStaging)
+			}
+
 		case "enter":
-			// Only submit from commit panel
-			if c.panel == panelCommit {
-				message := strings.TrimSpace(c.textarea.Value())
-				if message != "" && c.hasStagedFiles() {
-					return c, c.performCommit(message)
-				}
-				if message == "" {
-					c.err = fmt.Errorf("commit message cannot be empty")
-				} else if !c.hasStagedFiles() {
-					c.err = fmt.Errorf("no files staged for commit")
-				}
+			// Enter starts a new line in the body, the subject submits
+			if c.panel == panelBody {
+				break
+			}
+			if c.panel == panelSubject {
+				return c, c.submit()
+			}
+			return c, nil
+
+		case "ctrl+s":
+			if c.panel != panelStaging {
+				return c, c.submit()
 			}
 			return c, nil
 		}
 
 		// Panel-specific key handling
 		if c.panel == panelStaging {
-			switch msg.String() {
-			case "j", "down":
+			switch key := msg.String(); {
+			case c.keys.Down.Matches(key):
 				if c.cursor < len(c.files)-1 {
 					c.cursor++
 				}
 				return c, nil
 
-			case "k", "up":
+			case c.keys.Up.Matches(key):
 				if c.cursor > 0 {
 					c.cursor--
 				}
 				return c, nil
 
-			case " ":
+			case key == " ":
 				// Toggle staging
 				return c, c.toggleStage()
 
-			case "a":
+			case key == "a":
 				// Stage all
 				return c, c.stageAll()
+
+			case key == "d":
+				// Stage or unstage the selected file hunk by hunk
+				return c, c.openHunks()
+
+			case key == "A":
+				// Toggle amending the last commit
+				return c, c.toggleAmend()
+
+			case key == "E" && !c.amend:
+				// Toggle allowing an empty commit, e.g. to mark a milestone
+				c.allowEmpty = !c.allowEmpty
+				c.err = nil
+				return c, nil
+
+			case key == "N":
+				// Toggle skipping commit hooks
+				c.noVerify = !c.noVerify
+				return c, nil
 			}
 		}
 
 	case tea.WindowSizeMsg:
 		c.width = msg.Width
 		c.height = msg.Height
-		c.textarea.SetWidth(c.width - 10)
+		c.subjectInput.Width = c.width - 12
+		c.body.SetWidth(c.width - 10)
+		c.hookOutput.Width = c.width - 4 // inside the panel's border and padding
 
 	case errMsg:
 		c.err = msg.err
+		c.hookErr = nil
+		if errors.As(msg.err, &c.hookErr) {
+			output := expandTabs(c.hookErr.Output)
+			c.hookOutput.Height = min(strings.Count(output, "\n")+1, hookOutputLines)
+			c.hookOutput.SetContent(output)
+			c.hookOutput.GotoTop()
+		}
 		return c, nil
 	}
 
-	// Forward to textarea if in commit panel
-	if c.panel == panelCommit {
-		c.textarea, cmd = c.textarea.Update(msg)
+	// Forward to the focused message field
+	switch c.panel {
+	case panelSubject:
+		c.subjectInput, cmd = c.subjectInput.Update(msg)
+		return c, cmd
+	case panelBody:
+		c.body, cmd = c.body.Update(msg)
 		return c, cmd
 	}
 
 	return c, nil
 }
 
+// submit validates the message and opens the summary, or commits straight
+// away when committing all tracked changes
+func (c *CommitFlowView) submit() tea.Cmd {
+	message := c.message()
+	if message != "" && strings.TrimSpace(c.subjectInput.Value()) == "" {
+		c.err = fmt.Errorf("commit subject cannot be empty")
+		return nil
+	}
+
+	if c.amend {
+		// An empty message keeps the previous one
+		if message != "" {
+			message = git.BuildCommitMessage(message, c.trailers)
+		}
+		c.openSummary(message)
+		return nil
+	}
+	if message != "" && c.canCommit() {
+		message = git.BuildCommitMessage(message, c.trailers)
+		if c.commitAll {
+			// Everything tracked goes in, there is nothing to review
+			return c.guardProtected(c.performCommit(message))
+		}
+		c.openSummary(message)
+		return nil
+	}
+	if message == "" {
+		c.err = fmt.Errorf("commit message cannot be empty")
+	} else if !c.canCommit() {
+		c.err = fmt.Errorf("no files staged for commit")
+	}
+	return nil
+}
+
+// updateTrailerPicker handles keys while the trailer picker is open
+func (c *CommitFlowView) updateTrailerPicker(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		c.closeTrailerPicker()
+		return nil
+	}
+
+	if c.trailerPicker == trailerPickerKey {
+		switch key := msg.String(); {
+		case c.keys.Down.Matches(key):
+			if c.trailerCursor < len(c.trailerKeys)-1 {
+				c.trailerCursor++
+			}
+		case c.keys.Up.Matches(key):
+			if c.trailerCursor > 0 {
+				c.trailerCursor--
+			}
+		case key == "enter":
+			c.trailerPicker = trailerPickerValue
+			c.trailerInput.Prompt = c.trailerKeys[c.trailerCursor] + ": "
+			c.trailerInput.SetValue("")
+			return c.trailerInput.Focus()
+		}
+		return nil
+	}
+
+	if msg.String() == "enter" {
+		if value := strings.TrimSpace(c.trailerInput.Value()); value != "" {
+			c.trailers = append(c.trailers, models.Trailer{Key: c.trailerKeys[c.trailerCursor], Value: value})
+		}
+		c.closeTrailerPicker()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	c.trailerInput, cmd = c.trailerInput.Update(msg)
+	return cmd
+}
+
+func (c *CommitFlowView) closeTrailerPicker() {
+	c.trailerPicker = trailerPickerClosed
+	c.trailerInput.Blur()
+	c.focus(c.panel)
+}
+
+// updateTypePicker handles keys while the commit type picker is open
+func (c *CommitFlowView) updateTypePicker(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		c.closeTypePicker()
+		return nil
+	}
+
+	if c.typePicker == typePickerType {
+		switch key := msg.String(); {
+		case c.keys.Down.Matches(key):
+			if c.typeCursor < len(c.commitTypes)-1 {
+				c.typeCursor++
+			}
+		case c.keys.Up.Matches(key):
+			if c.typeCursor > 0 {
+				c.typeCursor--
+			}
+		case key == "enter":
+			c.typePicker = typePickerScope
+			c.scopeInput.SetValue("")
+			return c.scopeInput.Focus()
+		}
+		return nil
+	}
+
+	if msg.String() == "enter" {
+		c.applyCommitType(c.commitTypes[c.typeCursor], strings.TrimSpace(c.scopeInput.Value()))
+		c.closeTypePicker()
+		return c.focus(panelSubject)
+	}
+
+	var cmd tea.Cmd
+	c.scopeInput, cmd = c.scopeInput.Update(msg)
+	return cmd
+}
+
+func (c *CommitFlowView) closeTypePicker() {
+	c.typePicker = typePickerClosed
+	c.scopeInput.Blur()
+	c.focus(c.panel)
+}
+
+// applyCommitType puts a "type(scope): " prefix on the subject, replacing
+// any prefix already there but keeping its breaking-change marker
+func (c *CommitFlowView) applyCommitType(commitType, scope string) {
+	subject := c.subjectInput.Value()
+
+	prefix := commitType
+	if scope != "" {
+		prefix += "(" + scope + ")"
+	}
+	if m := conventionalPrefix.FindStringSubmatch(subject); m != nil && m[2] != "" {
+		prefix += "!"
+	}
+
+	c.subjectInput.SetValue(prefix + ": " + conventionalPrefix.ReplaceAllString(subject, ""))
+	c.subjectInput.CursorEnd()
+}
+
 func (c *CommitFlowView) toggleStage() tea.Cmd {
 	if c.cursor < 0 || c.cursor >= len(c.files) {
 		return nil
@@ -185,6 +687,48 @@ func (c *CommitFlowView) toggleStage() tea.Cmd {
 	}
 }
 
+// openHunks opens the selected file's hunks for staging one at a time
+func (c *CommitFlowView) openHunks() tea.Cmd {
+	if c.cursor < 0 || c.cursor >= len(c.files) {
+		return nil
+	}
+
+	file := c.files[c.cursor]
+	if file.IsUntracked || file.IsConflicted || file.IsIgnored {
+		c.err = fmt.Errorf("%s has no hunks to stage, stage it as a whole", file.Path)
+		return nil
+	}
+	c.hunkPath = file.Path
+	c.hunks = hunkList{}
+	c.err = nil
+	return func() tea.Msg { return loadFileDiff(file) }
+}
+
+// updateHunks handles keys while a file's hunks are open
+func (c *CommitFlowView) updateHunks(msg tea.KeyMsg) tea.Cmd {
+	switch key := msg.String(); {
+	case key == "esc" || key == "d":
+		c.hunkPath = ""
+
+	case c.keys.Down.Matches(key):
+		c.hunks.down()
+
+	case c.keys.Up.Matches(key):
+		c.hunks.up()
+
+	case key == " ":
+		// Reloading the files updates the staged count and the hunks
+		return c.hunks.toggle(c.hunkPath, func() tea.Msg {
+			files, err := git.GetWorkingTreeStatus()
+			if err != nil {
+				return errMsg{err}
+			}
+			return commitFlowFilesMsg{files}
+		})
+	}
+	return nil
+}
+
 func (c *CommitFlowView) stageAll() tea.Cmd {
 	return func() tea.Msg {
 		err := git.StageAll()
@@ -200,16 +744,274 @@ func (c *CommitFlowView) stageAll() tea.Cmd {
 	}
 }
 
+// guardProtected asks for confirmation before running commit on a
+// protected branch
+func (c *CommitFlowView) guardProtected(commit tea.Cmd) tea.Cmd {
+	if !c.protected {
+		return commit
+	}
+	prompt := fmt.Sprintf("%s is a protected branch. Commit to it anyway?\n\n"+
+		"To keep it clean, answer n, leave the commit flow and press T to move your changes to a new branch.", c.branch)
+	return requestConfirm(prompt, commit, nil)
+}
+
+// renderProtectedWarning renders the banner shown while on a protected branch
+func (c *CommitFlowView) renderProtectedWarning() string {
+	style := lipgloss.NewStyle().
+		Foreground(c.theme.Warning).
+		Background(c.theme.WarningBg).
+		Bold(true).
+		Padding(0, 1)
+	return style.Render(fmt.Sprintf("⚠  You are on protected branch %s, committing will ask for confirmation", c.branch))
+}
+
 func (c *CommitFlowView) performCommit(message string) tea.Cmd {
+	commitAll := c.commitAll
+	allowEmpty := c.allowEmpty
+	opts := c.commitOptions()
+	return c.runCommit(func() error {
+		if commitAll {
+			return git.CommitAll(message, opts)
+		}
+		if allowEmpty {
+			return git.CommitEmpty(message, opts)
+		}
+		return git.Commit(message, opts)
+	}, commitFlowDoneMsg{message: message})
+}
+
+func (c *CommitFlowView) commitOptions() git.CommitOptions {
+	return git.CommitOptions{Sign: c.cfg.SignCommits, NoVerify: c.noVerify}
+}
+
+// hookOutputLines is the height of the panel showing a rejecting hook's output
+const hookOutputLines = 10
+
+// updateHookOutput scrolls or dismisses the output of a rejecting hook,
+// reporting whether it used the key
+func (c *CommitFlowView) updateHookOutput(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc":
+		c.hookErr = nil
+		c.err = nil
+		return nil, true
+	case "pgdown", "pgup", "ctrl+d", "ctrl+u":
+		var cmd tea.Cmd
+		c.hookOutput, cmd = c.hookOutput.Update(msg)
+		return cmd, true
+	}
+	return nil, false
+}
+
+// renderError renders the last error, with the full output of a hook that
+// rejected the commit in a scrollable panel
+func (c *CommitFlowView) renderError() string {
+	errorStyle := lipgloss.NewStyle().Foreground(c.theme.StatusError)
+	if c.hookErr == nil {
+		return errorStyle.Render(fmt.Sprintf("Error: %v", c.err))
+	}
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(c.theme.Danger).
+		Padding(0, 1)
+	helpStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+
+	help := "pgup/pgdn: scroll • N: skip hooks (--no-verify) • esc: dismiss"
+	if c.noVerify {
+		help = "pgup/pgdn: scroll • N: run hooks again • esc: dismiss"
+	}
+	return errorStyle.Render(fmt.Sprintf("Error: %v", c.hookErr)) + "\n" +
+		panelStyle.Render(c.hookOutput.View()) + "\n" +
+		helpStyle.Render(help)
+}
+
+// optionNotes warns that commits skip the hooks or may be empty, empty
+// when they do neither
+func (c *CommitFlowView) optionNotes() string {
+	style := lipgloss.NewStyle().Foreground(c.theme.Warning).Bold(true)
+	note := ""
+	if c.allowEmpty && !c.amend {
+		note += style.Render(" allowing an empty commit (--allow-empty)")
+	}
+	if c.noVerify {
+		note += style.Render(" skipping hooks (--no-verify)")
+	}
+	return note
+}
+
+// runCommit runs commit, in the foreground when the commit gets signed so a
+// passphrase prompt can take over the terminal, and reports done on success
+func (c *CommitFlowView) runCommit(commit func() error, done commitFlowDoneMsg) tea.Cmd {
+	result := func(err error) tea.Msg {
+		if err != nil {
+			return errMsg{err}
+		}
+		return done
+	}
+
+	if c.cfg.SignCommits || git.SignsCommits() {
+		return tea.Exec(foregroundFunc(commit), result)
+	}
+	return func() tea.Msg { return result(commit()) }
+}
+
+// openSummary shows the staged files for a last review before committing
+func (c *CommitFlowView) openSummary(message string) {
+	c.summary = true
+	c.summaryMsg = message
+	c.summaryCursor = 0
+	c.summaryFiles = nil
+	c.savedPatches = map[string]string{}
+	for _, f := range c.files {
+		if f.IsStaged {
+			c.summaryFiles = append(c.summaryFiles, f.Path)
+		}
+	}
+	c.err = nil
+	c.blurMessage()
+}
+
+// updateSummary handles keys in the pre-commit summary
+func (c *CommitFlowView) updateSummary(msg tea.KeyMsg) tea.Cmd {
+	switch key := msg.String(); {
+	case key == "esc":
+		c.summary = false
+		return c.focus(c.panel)
+
+	case c.keys.Down.Matches(key):
+		if c.summaryCursor < len(c.summaryFiles)-1 {
+			c.summaryCursor++
+		}
+
+	case c.keys.Up.Matches(key):
+		if c.summaryCursor > 0 {
+			c.summaryCursor--
+		}
+
+	case key == " ":
+		// Unstage or restage the selected file
+		if c.summaryCursor < len(c.summaryFiles) {
+			path := c.summaryFiles[c.summaryCursor]
+			return c.stagePath(path, !c.isStaged(path))
+		}
+
+	case key == "N":
+		// Toggle skipping commit hooks, e.g. to retry past a failing one
+		c.noVerify = !c.noVerify
+
+	case key == "enter":
+		if c.amend {
+			return c.guardProtected(c.performAmend(c.summaryMsg))
+		}
+		if !c.hasStagedFiles() && !c.allowEmpty {
+			c.err = fmt.Errorf("no files staged for commit")
+			return nil
+		}
+		return c.guardProtected(c.performCommit(c.summaryMsg))
+	}
+
+	return nil
+}
+
+// isStaged reports whether path currently has staged changes
+func (c *CommitFlowView) isStaged(path string) bool {
+	for _, f := range c.files {
+		if f.Path == path {
+			return f.IsStaged
+		}
+	}
+	return false
+}
+
+// stagePath stages or unstages a single file from the summary and reloads
+// the status. Unstaging a partially staged file keeps its staged changes,
+// so restaging it puts back just those rather than the whole file.
+func (c *CommitFlowView) stagePath(path string, stage bool) tea.Cmd {
+	partial := false
+	for _, f := range c.files {
+		if f.Path == path {
+			partial = f.IsPartiallyStaged()
+		}
+	}
+	saved := c.savedPatches[path]
+
 	return func() tea.Msg {
-		err := git.Commit(message)
+		var patch string
+		var err error
+		switch {
+		case stage && saved != "":
+			err = git.ApplyStagedPatch(saved)
+		case stage:
+			err = git.StageFile(path)
+		default:
+			if partial {
+				if patch, err = git.GetStagedPatch(path); err != nil {
+					return errMsg{err}
+				}
+			}
+			err = git.UnstageFile(path)
+		}
+
+		if err != nil {
+			return errMsg{err}
+		}
+
+		files, err := git.GetWorkingTreeStatus()
 		if err != nil {
 			return errMsg{err}
 		}
-		return commitFlowDoneMsg{message: message}
+		return summaryToggledMsg{path: path, patch: patch, files: files}
 	}
 }
 
+// toggleAmend switches into amend mode, pre-filling the previous message,
+// or back to a new commit with the earlier draft restored
+func (c *CommitFlowView) toggleAmend() tea.Cmd {
+	if c.amend {
+		c.amend = false
+		c.setMessage(c.draft)
+		return nil
+	}
+
+	return func() tea.Msg {
+		message, err := git.GetLastCommitMessage()
+		return lastCommitMessageMsg{message: message, err: err}
+	}
+}
+
+func (c *CommitFlowView) performAmend(message string) tea.Cmd {
+	opts := c.commitOptions()
+	done := commitFlowDoneMsg{message: message, amended: true}
+	if message == "" {
+		done.message = c.amendMessage
+	}
+	return c.runCommit(func() error {
+		return git.CommitAmend(message, opts)
+	}, done)
+}
+
+// canCommit reports whether there is something to commit
+func (c *CommitFlowView) canCommit() bool {
+	if c.allowEmpty {
+		return true
+	}
+	if c.commitAll {
+		return c.hasTrackedChanges() || c.hasStagedFiles()
+	}
+	return c.hasStagedFiles()
+}
+
+// hasTrackedChanges reports whether any tracked file has unstaged changes
+func (c *CommitFlowView) hasTrackedChanges() bool {
+	for _, f := range c.files {
+		if !f.IsUntracked && f.Status != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *CommitFlowView) hasStagedFiles() bool {
 	for _, f := range c.files {
 		if f.IsStaged {
@@ -220,30 +1022,72 @@ func (c *CommitFlowView) hasStagedFiles() bool {
 }
 
 func (c *CommitFlowView) View() string {
-	if len(c.files) == 0 {
-		grayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-		return "\n" + grayStyle.Render("  No changes to commit. Press esc to go back.")
+	if len(c.files) == 0 && !c.amend && !c.allowEmpty {
+		grayStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+		view := "\n" + grayStyle.Render("  No changes to commit. Press A to amend the last commit, E to make an empty commit or esc to go back.")
+		if c.err != nil {
+			errorStyle := lipgloss.NewStyle().Foreground(c.theme.StatusError)
+			view += "\n\n" + errorStyle.Render(fmt.Sprintf("  Error: %v", c.err))
+		}
+		return view
+	}
+
+	if c.summary {
+		if c.protected {
+			return c.renderProtectedWarning() + "\n\n" + c.renderSummary()
+		}
+		return c.renderSummary()
 	}
 
 	var b strings.Builder
 
-	// Staging panel
-	b.WriteString(c.renderStagingPanel())
+	if c.protected {
+		b.WriteString(c.renderProtectedWarning() + "\n\n")
+	}
+
+	// Staging panel, or the hunks of one file while they are open
+	if c.hunkPath != "" {
+		b.WriteString(c.renderHunkPanel())
+	} else {
+		b.WriteString(c.renderStagingPanel())
+	}
 	b.WriteString("\n\n")
 
+	// Offer to commit all tracked changes when nothing is staged
+	if c.promptAll {
+		promptStyle := lipgloss.NewStyle().
+			Foreground(c.theme.Warning).
+			Bold(true)
+		b.WriteString(promptStyle.Render("Nothing is staged. Commit all tracked changes (git commit -a)? y/n"))
+		b.WriteString("\n\n")
+	}
+
 	// Commit panel
 	b.WriteString(c.renderCommitPanel())
 	b.WriteString("\n\n")
 
 	// Error message
 	if c.err != nil {
-		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", c.err)) + "\n\n")
+		b.WriteString(c.renderError() + "\n\n")
 	}
 
 	// Help text
-	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-	help := "space: toggle • a: stage all • tab: switch • enter: commit • esc: cancel"
+	helpStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+	help := "space: toggle • a: stage all • d: hunks • A: amend • E: empty commit • tab: next field • ctrl+y: type • ctrl+t: trailer • enter: commit • esc: cancel"
+	if c.amend {
+		help = "space: toggle • a: stage all • d: hunks • A: new commit • tab: next field • ctrl+y: type • ctrl+t: trailer • enter: amend • esc: cancel"
+	}
+	if c.panel == panelBody {
+		action := "commit"
+		if c.amend {
+			action = "amend"
+		}
+		help = "enter: new line • tab: next field • ctrl+y: type • ctrl+t: trailer • ctrl+s: " + action + " • esc: cancel"
+	}
+	if c.hunkPath != "" {
+		// The hunk panel has its own help line
+		help = "esc: back to files"
+	}
 	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
@@ -251,13 +1095,13 @@ func (c *CommitFlowView) View() string {
 
 func (c *CommitFlowView) renderStagingPanel() string {
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(c.theme.Accent).
 		Bold(true)
 
 	activeTitleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(c.theme.Accent).
 		Bold(true).
-		Background(lipgloss.Color("236"))
+		Background(c.theme.Panel)
 
 	// Count staged files
 	stagedCount := 0
@@ -268,6 +1112,9 @@ func (c *CommitFlowView) renderStagingPanel() string {
 	}
 
 	title := fmt.Sprintf(" Stage Files (%d/%d staged) ", stagedCount, len(c.files))
+	if c.commitAll {
+		title = " Stage Files (committing all tracked changes) "
+	}
 	if c.panel == panelStaging {
 		title = activeTitleStyle.Render(title)
 	} else {
@@ -278,10 +1125,10 @@ func (c *CommitFlowView) renderStagingPanel() string {
 	content.WriteString(title + "\n\n")
 
 	// File list with checkboxes
-	selectedStyle := lipgloss.NewStyle().Background(lipgloss.Color("236"))
-	stagedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-	unstagedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("white"))
-	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("yellow")).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Background(c.theme.Panel)
+	stagedStyle := lipgloss.NewStyle().Foreground(c.theme.StatusOK)
+	unstagedStyle := lipgloss.NewStyle().Foreground(c.theme.Text)
+	statusStyle := lipgloss.NewStyle().Foreground(c.theme.Highlight).Bold(true)
 
 	maxVisible := 8
 	start := 0
@@ -330,33 +1177,211 @@ func (c *CommitFlowView) renderStagingPanel() string {
 
 	// Show scroll indicator if needed
 	if len(c.files) > maxVisible {
-		scrollInfo := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+		scrollInfo := lipgloss.NewStyle().Foreground(c.theme.Muted)
 		content.WriteString(scrollInfo.Render(fmt.Sprintf("  ... %d more files", len(c.files)-maxVisible)))
 	}
 
 	return content.String()
 }
 
+// hunkPanelLines is the height of the hunk list in the commit flow
+const hunkPanelLines = 12
+
+// renderHunkPanel renders the open file's hunks in place of the file list
+func (c *CommitFlowView) renderHunkPanel() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(c.theme.Accent).
+		Bold(true).
+		Background(c.theme.Panel)
+
+	stagedCount := 0
+	for _, f := range c.files {
+		if f.IsStaged {
+			stagedCount++
+		}
+	}
+
+	title := titleStyle.Render(fmt.Sprintf(" Hunks of %s (%d/%d files staged) ", c.hunkPath, stagedCount, len(c.files)))
+	if len(c.hunks.items) == 0 {
+		loading := lipgloss.NewStyle().Foreground(c.theme.Muted).Render("Loading diff...")
+		return title + "\n\n" + loading
+	}
+	return title + "\n\n" + c.hunks.render(c.theme, c.width, hunkPanelLines, false, true, "esc: back to files")
+}
+
 func (c *CommitFlowView) renderCommitPanel() string {
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(c.theme.Accent).
 		Bold(true)
 
 	activeTitleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(c.theme.Accent).
 		Bold(true).
-		Background(lipgloss.Color("236"))
+		Background(c.theme.Panel)
 
 	title := " Commit Message "
-	if c.panel == panelCommit {
+	if c.amend {
+		title = " Amend Last Commit "
+	}
+	if c.panel != panelStaging {
 		title = activeTitleStyle.Render(title)
 	} else {
 		title = titleStyle.Render(title)
 	}
 
 	var content strings.Builder
-	content.WriteString(title + "\n\n")
-	content.WriteString(c.textarea.View())
+	content.WriteString(title + c.optionNotes() + "\n\n")
+	content.WriteString(c.subjectInput.View())
+
+	// Warn about long subjects, many tools cut them off
+	subject := strings.TrimSpace(c.subjectInput.Value())
+	if n := utf8.RuneCountInString(subject); n > maxSubjectLength {
+		warningStyle := lipgloss.NewStyle().Foreground(c.theme.Warning)
+		content.WriteString("\n" + warningStyle.Render(
+			fmt.Sprintf("Subject line is %d characters, consider keeping it to %d", n, maxSubjectLength)))
+	}
+
+	content.WriteString("\n\n" + c.body.View())
+
+	// Pending trailers, appended to the message on commit
+	if len(c.trailers) > 0 {
+		trailerStyle := lipgloss.NewStyle().Foreground(c.theme.Secondary)
+		content.WriteString("\n")
+		for _, t := range c.trailers {
+			content.WriteString("\n" + trailerStyle.Render(t.Key+": "+t.Value))
+		}
+	}
+
+	if c.trailerPicker != trailerPickerClosed {
+		content.WriteString("\n\n" + c.renderTrailerPicker())
+	}
+
+	if c.typePicker != typePickerClosed {
+		content.WriteString("\n\n" + c.renderTypePicker())
+	}
 
 	return content.String()
 }
+
+// renderSummary renders the pre-commit review of the message and staged files
+func (c *CommitFlowView) renderSummary() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(c.theme.Accent).
+		Bold(true).
+		Background(c.theme.Panel)
+	messageStyle := lipgloss.NewStyle().Foreground(c.theme.Text)
+	selectedStyle := lipgloss.NewStyle().Background(c.theme.Panel)
+	stagedStyle := lipgloss.NewStyle().Foreground(c.theme.StatusOK)
+	unstagedStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+	grayStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+
+	title := " Ready to Commit "
+	action := "commit"
+	if c.amend {
+		title = " Ready to Amend "
+		action = "amend"
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(title) + c.optionNotes() + "\n\n")
+
+	message := c.summaryMsg
+	if message == "" {
+		message = c.amendMessage
+	}
+	for _, line := range strings.Split(message, "\n") {
+		b.WriteString("  " + messageStyle.Render(line) + "\n")
+	}
+	b.WriteString("\n")
+
+	if len(c.summaryFiles) == 0 {
+		note := "  No staged changes, only the message will change"
+		if !c.amend {
+			note = "  No staged changes, this will be an empty commit"
+		}
+		b.WriteString(grayStyle.Render(note) + "\n")
+	}
+
+	staged := 0
+	for i, path := range c.summaryFiles {
+		checkbox := "[ ]"
+		style := unstagedStyle
+		if c.isStaged(path) {
+			checkbox = "[x]"
+			style = stagedStyle
+			staged++
+		}
+
+		cursor := "  "
+		if i == c.summaryCursor {
+			cursor = "> "
+		}
+
+		line := fmt.Sprintf("%s%s %s", cursor, checkbox, style.Render(path))
+		if i == c.summaryCursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	if len(c.summaryFiles) > 0 {
+		b.WriteString(grayStyle.Render(fmt.Sprintf("\n  %d of %d files will be committed", staged, len(c.summaryFiles))) + "\n")
+	}
+
+	if c.err != nil {
+		b.WriteString("\n" + c.renderError() + "\n")
+	}
+
+	b.WriteString("\n" + grayStyle.Render(fmt.Sprintf("space: include/exclude file • N: skip hooks • enter: %s • esc: edit message", action)))
+	return b.String()
+}
+
+func (c *CommitFlowView) renderTrailerPicker() string {
+	titleStyle := lipgloss.NewStyle().Foreground(c.theme.Accent).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Background(c.theme.Panel)
+	helpStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(" Add Trailer ") + "\n")
+
+	if c.trailerPicker == trailerPickerValue {
+		b.WriteString(c.trailerInput.View() + "\n")
+		b.WriteString(helpStyle.Render("enter: add • esc: cancel"))
+		return b.String()
+	}
+
+	for i, key := range c.trailerKeys {
+		line := "  " + key
+		if i == c.trailerCursor {
+			line = selectedStyle.Render("> " + key)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(helpStyle.Render("enter: select • esc: cancel"))
+	return b.String()
+}
+
+func (c *CommitFlowView) renderTypePicker() string {
+	titleStyle := lipgloss.NewStyle().Foreground(c.theme.Accent).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Background(c.theme.Panel)
+	helpStyle := lipgloss.NewStyle().Foreground(c.theme.Muted)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(" Commit Type ") + "\n")
+
+	if c.typePicker == typePickerScope {
+		b.WriteString(c.scopeInput.View() + "\n")
+		b.WriteString(helpStyle.Render("enter: apply " + c.commitTypes[c.typeCursor] + " • esc: cancel"))
+		return b.String()
+	}
+
+	for i, commitType := range c.commitTypes {
+		line := "  " + commitType
+		if i == c.typeCursor {
+			line = selectedStyle.Render("> " + commitType)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(helpStyle.Render("enter: select • esc: cancel"))
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// confirmMsg reports the user's answer to a ConfirmView
+type confirmMsg struct {
+	confirmed bool
+}
+
+// confirmRequestMsg asks the app to show a ConfirmView, so views other
+// than the app itself can guard destructive actions
+type confirmRequestMsg struct {
+	prompt string
+	onYes  tea.Cmd
+	onNo   tea.Cmd
+}
+
+// requestConfirm returns a command asking the app for confirmation
+func requestConfirm(prompt string, onYes, onNo tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return confirmRequestMsg{prompt: prompt, onYes: onYes, onNo: onNo}
+	}
+}
+
+// ConfirmView asks a yes/no question in a centered box. The app runs onYes
+// or onNo once the answer arrives as a confirmMsg.
+type ConfirmView struct {
+	theme  *Theme
+	prompt string
+	onYes  tea.Cmd
+	onNo   tea.Cmd
+	width  int
+	height int
+}
+
+func NewConfirmView(prompt string, onYes, onNo tea.Cmd, theme *Theme) *ConfirmView {
+	return &ConfirmView{
+		theme:  theme,
+		prompt: prompt,
+		onYes:  onYes,
+		onNo:   onNo,
+	}
+}
+
+func (c *ConfirmView) Init() tea.Cmd {
+	return nil
+}
+
+func (c *ConfirmView) Update(msg tea.Msg) (*ConfirmView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "Y":
+			return c, func() tea.Msg { return confirmMsg{confirmed: true} }
+		case "n", "N", "esc":
+			return c, func() tea.Msg { return confirmMsg{confirmed: false} }
+		}
+
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+	}
+
+	return c, nil
+}
+
+// Result returns the callback for the given answer
+func (c *ConfirmView) Result(confirmed bool) tea.Cmd {
+	if confirmed {
+		return c.onYes
+	}
+	return c.onNo
+}
+
+func (c *ConfirmView) View() string {
+	promptStyle := lipgloss.NewStyle().
+		Foreground(c.theme.Text).
+		Bold(true)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(c.theme.Muted)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(c.theme.Warning).
+		Padding(1, 3)
+
+	maxWidth := c.width - 10
+	if maxWidth < 20 {
+		maxWidth = 20
+	}
+	if maxWidth > 70 {
+		maxWidth = 70
+	}
+
+	content := promptStyle.Width(maxWidth).Render(c.prompt) + "\n\n" +
+		helpStyle.Render("y: yes • n: no")
+	box := boxStyle.Render(content)
+
+	if c.width == 0 || c.height == 0 {
+		return box
+	}
+	return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -5,8 +5,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/sync/errgroup"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
 	"github.com/Johannes-Berggren/GitGoblin/internal/git"
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
@@ -19,34 +23,71 @@ const (
 )
 
 type DashboardView struct {
+	theme           *Theme
+	cfg             *config.Config
 	repoName        string
+	remote          string
 	branch          string
-	files           []models.FileChange
+	files           []models.FileChange // files shown, after exclude patterns
+	allFiles        []models.FileChange
+	hiddenCount     int
+	showHidden      bool
+	wrapBranch      bool // wrap long branch names instead of truncating them
+	deltaStats      bool // show line stats as a single net delta
+	trackedOnly     bool // leave untracked files out of the file count
+	showIgnored     bool // list ignored files too
+	paused          bool // auto-refresh is paused
+	showStats       bool // show the history stats metric
+	fileOffset      int  // first file list row shown when the list is taller than the screen
+	maxFileOffset   int  // last offset that still fills the screen, as of the last render
 	aheadCount      int
 	behindCount     int
-	lastCommitTime  time.Time
+	hasUpstream     bool
+	lastCommit      *models.Commit // HEAD, nil before the first commit
+	emptyRepo       bool           // no commits yet, so nothing to compare against
 	linesAdded      int
 	linesDeleted    int
 	fileStats       map[string][2]int
+	historyStats    *models.HistoryStats
 	defaultBranch   string
 	aheadOfDefault  int
 	behindOfDefault int
 	isDefaultBranch bool
+	detached        bool // HEAD is not on a branch, branch holds its short hash
+	repoState       models.RepoState
+	mergeBase       *models.Commit // fork point from the default branch
+	totalAdded      int
+	totalDeleted    int
+	loading         bool      // a refresh is running
+	pending         bool      // another refresh was requested while loading
+	loadStarted     time.Time // when the running refresh started
+	spinner         spinner.Model
 	width           int
 	height          int
 }
 
-func NewDashboardView() *DashboardView {
-	return &DashboardView{}
+func NewDashboardView(cfg *config.Config, theme *Theme) *DashboardView {
+	return &DashboardView{
+		theme:       theme,
+		cfg:         cfg,
+		deltaStats:  cfg.DeltaLineStats,
+		trackedOnly: cfg.CountTrackedOnly,
+		paused:      cfg.RefreshInterval <= 0,
+		showStats:   cfg.ShowHistoryStats,
+		spinner:     newSpinner(theme),
+	}
 }
 
 type dashboardDataMsg struct {
 	repoName        string
+	remote          string
 	branch          string
 	files           []models.FileChange
 	aheadCount      int
 	behindCount     int
-	lastCommitTime  time.Time
+	hasUpstream     bool
+	lastCommit      *models.Commit
+	emptyRepo       bool
 	linesAdded      int
 	linesDeleted    int
 	fileStats       map[string][2]int
@@ -54,75 +95,178 @@ type dashboardDataMsg struct {
 	aheadOfDefault  int
 	behindOfDefault int
 	isDefaultBranch bool
+	detached        bool
+	repoState       models.RepoState
+	mergeBase       *models.Commit
+}
+
+// historyStatsMsg carries the history stats, counted apart from the
+// regular refresh
+type historyStatsMsg struct {
+	stats models.HistoryStats
+	err   error
 }
 
 func (d *DashboardView) Init() tea.Cmd {
-	return d.loadData()
+	if d.showStats {
+		return tea.Batch(d.refresh(), loadHistoryStats)
+	}
+	return d.refresh()
 }
 
-func (d *DashboardView) loadData() tea.Cmd {
-	return func() tea.Msg {
-		repoName, err := git.GetRepoName()
-		if err != nil {
-			repoName = ""
-		}
+func loadHistoryStats() tea.Msg {
+	stats, err := git.GetHistoryStats()
+	return historyStatsMsg{stats: stats, err: err}
+}
 
-		branch, err := git.GetCurrentBranch()
-		if err != nil {
-			branch = "unknown"
-		}
+// staleAfter is how long a refresh runs before the status box shows a
+// spinner, so quick refreshes don't make it flicker
+const staleAfter = 500 * time.Millisecond
+
+// refresh reloads the dashboard unless a load is already running, in which
+// case one more load is queued for when it finishes. Any number of requests
+// made meanwhile coalesce into that single load.
+func (d *DashboardView) refresh() tea.Cmd {
+	if d.loading {
+		d.pending = true
+		return nil
+	}
+	d.loading = true
+	d.loadStarted = time.Now()
+	return tea.Batch(d.loadData(), d.spinner.Tick)
+}
 
-		files, err := git.GetWorkingTreeStatus()
-		if err != nil {
-			files = []models.FileChange{}
-		}
+// Refreshing reports whether a load is running
+func (d *DashboardView) Refreshing() bool {
+	return d.loading
+}
 
-		// Get upstream status
-		branches, err := git.GetBranches()
-		ahead, behind := 0, 0
-		if err == nil {
-			for _, b := range branches {
-				if b.IsCurrent && b.Upstream != "" {
-					ahead, behind = parseUpstream(b.Upstream)
-					break
-				}
-			}
-		}
+// ToggleStats shows or hides the history stats, counting them afresh each
+// time they are shown since counting is too slow for every refresh
+func (d *DashboardView) ToggleStats() tea.Cmd {
+	d.showStats = !d.showStats
+	if !d.showStats {
+		return nil
+	}
+	return loadHistoryStats
+}
 
-		// Get last commit time
-		lastCommitTime, err := git.GetLastCommitTime()
-		if err != nil {
-			lastCommitTime = time.Time{}
-		}
+// TogglePaused pauses or resumes refreshing on every tick
+func (d *DashboardView) TogglePaused() {
+	d.paused = !d.paused
+}
 
-		// Get line stats (per-file)
-		fileStats, err := git.GetLineStats()
-		if err != nil {
-			fileStats = make(map[string][2]int)
-		}
+// Paused reports whether auto-refresh is paused
+func (d *DashboardView) Paused() bool {
+	return d.paused
+}
 
-		// Calculate totals for status box
-		linesAdded := 0
-		linesDeleted := 0
-		for _, stats := range fileStats {
-			linesAdded += stats[0]
-			linesDeleted += stats[1]
-		}
+// renderLogo renders the footer's logo, preceded by a note while
+// auto-refresh is paused
+func (d *DashboardView) renderLogo() string {
+	logo := lipgloss.NewStyle().Foreground(d.theme.Brand).Render("🧙 GitGoblin")
+	if d.paused {
+		logo = lipgloss.NewStyle().Foreground(d.theme.Warning).Render("⏸ paused") + "  " + logo
+	}
+	return logo
+}
+
+// loadData runs the dashboard's git queries concurrently. Failed queries
+// fall back to empty values rather than failing the whole refresh.
+func (d *DashboardView) loadData() tea.Cmd {
+	opts := git.StatusOptions{Ignored: d.showIgnored}
+	return func() tea.Msg {
+		var data dashboardDataMsg
+		var g errgroup.Group
+
+		g.Go(func() error {
+			data.repoName, _ = git.GetRepoName()
+			data.remote = git.DefaultRemote()
+			return nil
+		})
+
+		g.Go(func() error {
+			files, err := git.GetWorkingTreeStatusWith(opts)
+			if err != nil {
+				files = []models.FileChange{}
+			}
+			data.files = files
+			return nil
+		})
 
-		// Get default branch comparison
-		defaultBranch, err := git.GetDefaultBranch()
-		aheadOfDefault := 0
-		behindOfDefault := 0
-		isDefaultBranch := false
+		// Get upstream status
+		g.Go(func() error {
+			data.aheadCount, data.behindCount, data.hasUpstream, _ = git.GetUpstreamComparison()
+			return nil
+		})
+
+		g.Go(func() error {
+			// Fails in a repository without commits, leaving it nil
+			if commit, err := git.GetLastCommit(); err == nil {
+				data.lastCommit = &commit
+			}
+			return nil
+		})
+
+		// Get line stats (per-file) and totals for the status box
+		g.Go(func() error {
+			fileStats, err := git.GetLineStats()
+			if err != nil {
+				fileStats = make(map[string][2]int)
+			}
+			for _, stats := range fileStats {
+				data.linesAdded += stats[0]
+				data.linesDeleted += stats[1]
+			}
+			data.fileStats = fileStats
+			return nil
+		})
+
+		// Get the branch and its comparison to the default branch
+		g.Go(func() error {
+			branch, detached, err := git.GetHead()
+			if err != nil {
+				branch = "unknown"
+			}
+			data.branch = branch
+			data.detached = detached
 
-		if err == nil {
-			isDefaultBranch = (branch == defaultBranch)
-			if !isDefaultBranch {
-				aheadOfDefault, behindOfDefault, _ = git.GetBranchComparison(branch, defaultBranch)
+			// A detached HEAD isn't a branch to compare with the default one
+			if detached {
+				return nil
+			}
+
+			// An unborn branch has no history to compare
+			if empty, _ := git.IsEmptyRepo(); empty {
+				data.emptyRepo = true
+				return nil
 			}
-		}
 
-		return dashboardDataMsg{repoName, branch, files, ahead, behind, lastCommitTime, linesAdded, linesDeleted, fileStats, defaultBranch, aheadOfDefault, behindOfDefault, isDefaultBranch}
+			defaultBranch, err := git.GetDefaultBranch()
+			if err != nil {
+				return nil
+			}
+			data.defaultBranch = defaultBranch
+			data.isDefaultBranch = branch == defaultBranch
+			if !data.isDefaultBranch {
+				data.aheadOfDefault, data.behindOfDefault, _ = git.GetBranchComparison(branch, defaultBranch)
+				if base, err := git.GetMergeBase("HEAD", git.DefaultRemote()+"/"+defaultBranch); err == nil {
+					data.mergeBase = &base
+				}
+			}
+			return nil
+		})
+
+		// Get in-progress operation and detached HEAD state
+		g.Go(func() error {
+			data.repoState, _ = git.GetRepoState()
+			return nil
+		})
+
+		// Each query writes its own fields, so waiting is all the syncing needed
+		_ = g.Wait()
+		rollUpDirStats(data.files, data.fileStats)
+		return data
 	}
 }
 
@@ -130,18 +274,45 @@ func (d *DashboardView) Update(msg tea.Msg) (*DashboardView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case dashboardDataMsg:
 		d.repoName = msg.repoName
+		d.remote = msg.remote
 		d.branch = msg.branch
-		d.files = msg.files
+		d.allFiles = msg.files
 		d.aheadCount = msg.aheadCount
 		d.behindCount = msg.behindCount
-		d.lastCommitTime = msg.lastCommitTime
-		d.linesAdded = msg.linesAdded
-		d.linesDeleted = msg.linesDeleted
+		d.hasUpstream = msg.hasUpstream
+		d.lastCommit = msg.lastCommit
+		d.emptyRepo = msg.emptyRepo
+		d.totalAdded = msg.linesAdded
+		d.totalDeleted = msg.linesDeleted
 		d.fileStats = msg.fileStats
 		d.defaultBranch = msg.defaultBranch
 		d.aheadOfDefault = msg.aheadOfDefault
 		d.behindOfDefault = msg.behindOfDefault
 		d.isDefaultBranch = msg.isDefaultBranch
+		d.detached = msg.detached
+		d.repoState = msg.repoState
+		d.mergeBase = msg.mergeBase
+		d.applyExcludes()
+
+		d.loading = false
+		if d.pending {
+			// Something changed while loading, load once more
+			d.pending = false
+			return d, d.refresh()
+		}
+
+	case historyStatsMsg:
+		// Keep showing the last counts should counting fail
+		if msg.err == nil {
+			d.historyStats = &msg.stats
+		}
+
+	case spinner.TickMsg:
+		if d.loading {
+			var cmd tea.Cmd
+			d.spinner, cmd = d.spinner.Update(msg)
+			return d, cmd
+		}
 
 	case tea.WindowSizeMsg:
 		d.width = msg.Width
@@ -151,6 +322,222 @@ func (d *DashboardView) Update(msg tea.Msg) (*DashboardView, tea.Cmd) {
 	return d, nil
 }
 
+// rollUpDirStats gives each collapsed untracked directory the summed line
+// stats of the files inside it, which are counted per file
+func rollUpDirStats(files []models.FileChange, fileStats map[string][2]int) {
+	for _, file := range files {
+		if !file.IsDir() || file.IsIgnored {
+			continue
+		}
+		var total [2]int
+		for path, stats := range fileStats {
+			if strings.HasPrefix(path, file.Path) {
+				total[0] += stats[0]
+				total[1] += stats[1]
+			}
+		}
+		fileStats[file.Path] = total
+	}
+}
+
+// stale reports whether a refresh has been running long enough to show
+// the spinner
+func (d *DashboardView) stale() bool {
+	return d.loading && time.Since(d.loadStarted) >= staleAfter
+}
+
+// ScrollFiles moves the file list window by delta rows, staying within the list
+func (d *DashboardView) ScrollFiles(delta int) {
+	d.fileOffset = max(0, min(d.fileOffset+delta, d.maxFileOffset))
+}
+
+// ScrollFilesToEdge jumps to the top or bottom of the file list
+func (d *DashboardView) ScrollFilesToEdge(bottom bool) {
+	d.fileOffset = 0
+	if bottom {
+		d.fileOffset = d.maxFileOffset
+	}
+}
+
+// ToggleBranchWrap switches long branch names between wrapping and middle truncation
+func (d *DashboardView) ToggleBranchWrap() {
+	d.wrapBranch = !d.wrapBranch
+}
+
+// ToggleDeltaStats switches line stats between +added/-deleted and a net delta
+func (d *DashboardView) ToggleDeltaStats() {
+	d.deltaStats = !d.deltaStats
+}
+
+// ToggleTrackedOnly switches the file count between all changed files and
+// tracked files only
+func (d *DashboardView) ToggleTrackedOnly() {
+	d.trackedOnly = !d.trackedOnly
+}
+
+// fileCount returns the headline file count, without untracked files when
+// only tracked changes are counted, and how many untracked files it left
+// out. Ignored files are never counted.
+func (d *DashboardView) fileCount() (count, untracked int) {
+	ignored := 0
+	for _, file := range d.files {
+		switch {
+		case file.IsUntracked:
+			untracked++
+		case file.IsIgnored:
+			ignored++
+		}
+	}
+	if d.trackedOnly {
+		return len(d.files) - untracked - ignored, untracked
+	}
+	return len(d.files) - ignored, 0
+}
+
+// fileCountText renders the file count for the compact layouts, e.g.
+// "3 files" or "3 tracked files"
+func (d *DashboardView) fileCountText() string {
+	count, _ := d.fileCount()
+	if d.trackedOnly {
+		return fmt.Sprintf("%d tracked files", count)
+	}
+	return fmt.Sprintf("%d files", count)
+}
+
+// renderLineDelta renders the net line change as one signed number
+func (d *DashboardView) renderLineDelta() string {
+	net := d.linesAdded - d.linesDeleted
+	switch {
+	case net > 0:
+		return lipgloss.NewStyle().Foreground(d.theme.Success).Bold(true).Render(fmt.Sprintf("+%d", net))
+	case net < 0:
+		return lipgloss.NewStyle().Foreground(d.theme.Danger).Bold(true).Render(fmt.Sprintf("−%d", -net))
+	}
+	return lipgloss.NewStyle().Foreground(d.theme.Subtle).Render("±0")
+}
+
+// ToggleIgnored switches between listing and leaving out ignored files,
+// reloading the file list
+func (d *DashboardView) ToggleIgnored() tea.Cmd {
+	d.showIgnored = !d.showIgnored
+	return d.refresh()
+}
+
+// ToggleHidden switches between hiding and showing files matched by exclude_paths
+func (d *DashboardView) ToggleHidden() {
+	d.showHidden = !d.showHidden
+	d.applyExcludes()
+}
+
+// applyExcludes filters the file list against the configured exclude
+// patterns, drops the hidden files' lines from the totals and orders the
+// list by section
+func (d *DashboardView) applyExcludes() {
+	d.files = groupedFiles(d.allFiles)
+	d.hiddenCount = 0
+	d.linesAdded = d.totalAdded
+	d.linesDeleted = d.totalDeleted
+
+	if d.showHidden || len(d.cfg.ExcludePaths) == 0 {
+		return
+	}
+
+	d.files = []models.FileChange{}
+	for _, file := range d.allFiles {
+		if d.cfg.IsExcluded(file.Path) {
+			d.hiddenCount++
+			if stats, ok := d.fileStats[file.Path]; ok {
+				d.linesAdded -= stats[0]
+				d.linesDeleted -= stats[1]
+			}
+			continue
+		}
+		d.files = append(d.files, file)
+	}
+	d.files = groupedFiles(d.files)
+}
+
+// renderStatus renders a file's two-letter status in style. The staged
+// letter of a partially staged file is green, so "MM" reads as changes both
+// in the index and in the working tree.
+func (d *DashboardView) renderStatus(file *models.FileChange, style lipgloss.Style) string {
+	status := file.DisplayStatus()
+	if !file.IsPartiallyStaged() {
+		return style.Render(status)
+	}
+	stagedStyle := lipgloss.NewStyle().Foreground(d.theme.Success).Bold(true)
+	return stagedStyle.Render(status[:1]) + style.Render(status[1:])
+}
+
+// partialMark returns a "◐ partly staged" suffix for files with changes left
+// out of the next commit, or "" for the rest
+func (d *DashboardView) partialMark(file *models.FileChange) string {
+	if !file.IsPartiallyStaged() {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(d.theme.Warning).Render(" ◐ partly staged")
+}
+
+// hiddenNote returns a muted "(N hidden)" suffix for file list titles
+func (d *DashboardView) hiddenNote() string {
+	if d.hiddenCount == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(d.theme.Subtle).
+		Render(fmt.Sprintf(" (%d hidden)", d.hiddenCount))
+}
+
+// branchLabel returns the branch name, or the detached commit when HEAD is detached
+func (d *DashboardView) branchLabel() string {
+	if d.detached {
+		return "detached @ " + d.branch
+	}
+	return d.branch
+}
+
+// operationText describes an in-progress operation, e.g. "REBASE IN PROGRESS (3/7)"
+func (d *DashboardView) operationText() string {
+	state := d.repoState
+	if !state.InProgress() {
+		return ""
+	}
+	text := strings.ToUpper(state.Operation.String()) + " IN PROGRESS"
+	if state.Total > 0 {
+		text += fmt.Sprintf(" (%d/%d)", state.Step, state.Total)
+	}
+	return text
+}
+
+// operationDetail explains where HEAD is during an in-progress operation
+func (d *DashboardView) operationDetail() string {
+	state := d.repoState
+	var parts []string
+	if state.Detached {
+		parts = append(parts, "HEAD detached at "+state.HeadShort)
+	}
+	if state.RebaseBranch != "" {
+		parts = append(parts, "rebasing "+state.RebaseBranch)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// operationHint tells the user how to finish or back out of an in-progress
+// operation
+func (d *DashboardView) operationHint() string {
+	switch d.repoState.Operation {
+	case models.OperationMerge:
+		return "Resolve conflicts and stage them, then commit to finish or press A to abort"
+	case models.OperationRebase:
+		return "Resolve conflicts, stage them and run git rebase --continue, or git rebase --abort"
+	case models.OperationCherryPick:
+		return "Resolve conflicts and stage them, then press C to continue or A to abort"
+	case models.OperationRevert:
+		return "Resolve conflicts and stage them, then press C to continue or A to abort"
+	}
+	return ""
+}
+
 // getDisplayMode determines which display mode to use based on terminal height
 func (d *DashboardView) getDisplayMode() int {
 	if d.height >= 20 {
@@ -163,10 +550,10 @@ func (d *DashboardView) getDisplayMode() int {
 
 // formatTimeSinceCommit returns a human-readable time since last commit
 func (d *DashboardView) formatTimeSinceCommit() string {
-	if d.lastCommitTime.IsZero() {
-		return "n/a"
+	if d.lastCommit == nil {
+		return "no commits yet"
 	}
-	duration := time.Since(d.lastCommitTime)
+	duration := time.Since(d.lastCommit.CommitDate)
 	if duration.Hours() < 1 {
 		return fmt.Sprintf("%.0fm ago", duration.Minutes())
 	} else if duration.Hours() < 24 {
@@ -178,26 +565,24 @@ func (d *DashboardView) formatTimeSinceCommit() string {
 
 // renderStatusBox creates a bordered box with development metrics
 func (d *DashboardView) renderStatusBox() string {
-	timeSinceCommit := d.formatTimeSinceCommit()
-
 	// Create metrics display with emoji icons
 	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(d.theme.Accent).
 		Bold(true)
 
 	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("white"))
+		Foreground(d.theme.Text)
 
 	greenStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("34")).
+		Foreground(d.theme.Success).
 		Bold(true)
 
 	redStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196")).
+		Foreground(d.theme.Danger).
 		Bold(true)
 
 	grayStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240"))
+		Foreground(d.theme.Subtle)
 
 	// Build line stats with colored numbers (gray for zeros)
 	var addedText, deletedText string
@@ -214,18 +599,31 @@ func (d *DashboardView) renderStatusBox() string {
 	}
 
 	lineStats := fmt.Sprintf("%s/%s", addedText, deletedText)
+	if d.deltaStats {
+		lineStats = d.renderLineDelta()
+	}
+
+	fileCount, untracked := d.fileCount()
+	filesLabel := "Files Changed:"
+	filesValue := valueStyle.Render(fmt.Sprintf("%d", fileCount))
+	if d.trackedOnly {
+		filesLabel = "Tracked Files Changed:"
+		if untracked > 0 {
+			filesValue += grayStyle.Render(fmt.Sprintf(" (+%d untracked)", untracked))
+		}
+	}
 
 	metrics := []string{
-		fmt.Sprintf("📁 %s %s", labelStyle.Render("Files Changed:"), valueStyle.Render(fmt.Sprintf("%d", len(d.files)))),
-		fmt.Sprintf("⏰ %s %s", labelStyle.Render("Last Commit:"), valueStyle.Render(timeSinceCommit)),
-		fmt.Sprintf("⬆️  %s %s", labelStyle.Render("Commits Ahead:"), valueStyle.Render(fmt.Sprintf("%d", d.aheadCount))),
+		fmt.Sprintf("📁 %s %s", labelStyle.Render(filesLabel), filesValue),
+		fmt.Sprintf("⏰ %s %s", labelStyle.Render("Last Commit:"), d.renderLastCommit()),
+		fmt.Sprintf("⬆️  %s %s", labelStyle.Render("Commits Ahead:"), d.renderCommitsAhead()),
 		fmt.Sprintf("📊 %s %s", labelStyle.Render("Lines:"), lineStats),
 	}
 
 	// Add default branch comparison if not on default branch
 	if !d.isDefaultBranch && d.defaultBranch != "" {
 		orangeStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("214")).
+			Foreground(d.theme.Warning).
 			Bold(true)
 
 		defaultBranchMetric := fmt.Sprintf("🎯 %s %s: %s %s",
@@ -235,6 +633,36 @@ func (d *DashboardView) renderStatusBox() string {
 			orangeStyle.Render(fmt.Sprintf("↓%d", d.behindOfDefault)),
 		)
 		metrics = append(metrics, defaultBranchMetric)
+
+		// Show how stale the branch point is
+		if d.mergeBase != nil {
+			grayStyle := lipgloss.NewStyle().Foreground(d.theme.Muted)
+			yellowStyle := lipgloss.NewStyle().Foreground(d.theme.Highlight)
+			subject := d.mergeBase.Message
+			if lipgloss.Width(subject) > 40 {
+				subject = ansi.Truncate(subject, 40, "…")
+			}
+			metrics = append(metrics, fmt.Sprintf("🔀 %s %s %s %s",
+				labelStyle.Render("Base:"),
+				yellowStyle.Render(d.mergeBase.ShortHash),
+				valueStyle.Render(subject),
+				grayStyle.Render("("+formatRelativeTime(d.mergeBase.Date)+")"),
+			))
+		}
+	}
+
+	if d.showStats && d.historyStats != nil {
+		s := d.historyStats
+		metrics = append(metrics, fmt.Sprintf("📚 %s %s",
+			labelStyle.Render("History:"),
+			valueStyle.Render(fmt.Sprintf("%d commits • %d branches • %d tags • %d contributors",
+				s.Commits, s.Branches, s.Tags, s.Contributors)),
+		))
+	}
+
+	// Large repos can take a while to refresh, say so rather than look stuck
+	if d.stale() {
+		metrics = append(metrics, d.spinner.View()+grayStyle.Render(" Refreshing..."))
 	}
 
 	content := strings.Join(metrics, "\n")
@@ -242,7 +670,7 @@ func (d *DashboardView) renderStatusBox() string {
 	// Create bordered box with subtle colors
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")). // Subtle gray instead of bright magenta
+		BorderForeground(d.theme.Border). // Subtle gray instead of bright magenta
 		Padding(1, 2).
 		MarginLeft(5).
 		MarginBottom(1)
@@ -250,24 +678,75 @@ func (d *DashboardView) renderStatusBox() string {
 	return boxStyle.Render(content)
 }
 
+// commitsAhead returns how many commits the branch is ahead of its upstream,
+// or of the default branch when it has none, and what that was against
+func (d *DashboardView) commitsAhead() (int, string) {
+	if d.hasUpstream || d.isDefaultBranch || d.defaultBranch == "" {
+		return d.aheadCount, ""
+	}
+	return d.aheadOfDefault, d.defaultBranch
+}
+
+// renderCommitsAhead renders the Commits Ahead metric, noting when it falls
+// back to the default branch for lack of an upstream
+func (d *DashboardView) renderCommitsAhead() string {
+	valueStyle := lipgloss.NewStyle().Foreground(d.theme.Text)
+	grayStyle := lipgloss.NewStyle().Foreground(d.theme.Muted)
+
+	ahead, against := d.commitsAhead()
+	text := valueStyle.Render(fmt.Sprintf("%d", ahead))
+	if against != "" {
+		text += grayStyle.Render(" (vs " + against + ", no upstream)")
+	}
+	return text
+}
+
+// renderLastCommit renders HEAD's short hash and subject with how long ago
+// it was committed, so it's clear the last commit landed
+func (d *DashboardView) renderLastCommit() string {
+	grayStyle := lipgloss.NewStyle().Foreground(d.theme.Muted)
+	if d.lastCommit == nil {
+		return grayStyle.Render(d.formatTimeSinceCommit())
+	}
+
+	yellowStyle := lipgloss.NewStyle().Foreground(d.theme.Highlight)
+	valueStyle := lipgloss.NewStyle().Foreground(d.theme.Text)
+	subject := d.lastCommit.Message
+	if lipgloss.Width(subject) > 40 {
+		subject = ansi.Truncate(subject, 40, "…")
+	}
+	return fmt.Sprintf("%s %s %s",
+		yellowStyle.Render(d.lastCommit.ShortHash),
+		valueStyle.Render(subject),
+		grayStyle.Render("("+d.formatTimeSinceCommit()+")"),
+	)
+}
+
 // renderCompactBranchLine renders branch name and warning on a single line
 func (d *DashboardView) renderCompactBranchLine() string {
 	branchStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("cyan"))
+		Foreground(d.theme.Accent)
 
 	warningStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("yellow")).
+		Foreground(d.theme.Highlight).
 		Bold(true)
 
-	line := fmt.Sprintf("  🌿 %s", branchStyle.Render(d.branch))
+	line := fmt.Sprintf("  🌿 %s", branchStyle.Render(d.branchLabel()))
+
+	if op := d.operationText(); op != "" {
+		line += "  " + lipgloss.NewStyle().
+			Foreground(d.theme.Danger).
+			Bold(true).
+			Render("⚠ "+op)
+	}
 
 	if d.behindCount > 0 {
 		// Add spacing and warning
-		warning := warningStyle.Render(fmt.Sprintf("⚠ ↓%d behind origin", d.behindCount))
+		warning := warningStyle.Render(fmt.Sprintf("⚠ ↓%d behind %s", d.behindCount, d.remote))
 		// Calculate spacing to spread across width
-		lineLen := 5 + len(d.branch) // "  🌿 " + branch
-		warningLen := 15 + len(fmt.Sprintf("%d", d.behindCount))
+		lineLen := lipgloss.Width(line) // "  🌿 " + branch
+		warningLen := lipgloss.Width(warning)
 		spacing := d.width - lineLen - warningLen - 2
 		if spacing < 2 {
 			spacing = 2
@@ -280,9 +759,9 @@ func (d *DashboardView) renderCompactBranchLine() string {
 
 // renderCompactMetricsLine renders all metrics horizontally on one line
 func (d *DashboardView) renderCompactMetricsLine() string {
-	greenStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("34")).Bold(true)
-	redStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-	grayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	greenStyle := lipgloss.NewStyle().Foreground(d.theme.Success).Bold(true)
+	redStyle := lipgloss.NewStyle().Foreground(d.theme.Danger).Bold(true)
+	grayStyle := lipgloss.NewStyle().Foreground(d.theme.Subtle)
 
 	// Build line stats
 	var addedText, deletedText string
@@ -297,13 +776,18 @@ func (d *DashboardView) renderCompactMetricsLine() string {
 		deletedText = grayStyle.Render("-0")
 	}
 
+	lineStats := fmt.Sprintf("%s/%s", addedText, deletedText)
+	if d.deltaStats {
+		lineStats = d.renderLineDelta()
+	}
+
 	parts := []string{
-		fmt.Sprintf("📁 %d files", len(d.files)),
-		fmt.Sprintf("📊 %s/%s", addedText, deletedText),
+		"📁 " + d.fileCountText(),
+		fmt.Sprintf("📊 %s", lineStats),
 	}
 
-	if d.aheadCount > 0 {
-		parts = append(parts, fmt.Sprintf("⬆ %d ahead", d.aheadCount))
+	if ahead, _ := d.commitsAhead(); ahead > 0 {
+		parts = append(parts, fmt.Sprintf("⬆ %d ahead", ahead))
 	}
 
 	parts = append(parts, fmt.Sprintf("⏰ %s", d.formatTimeSinceCommit()))
@@ -318,27 +802,27 @@ func (d *DashboardView) renderCompactFileList(maxFiles int) string {
 	}
 
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(d.theme.Accent).
 		Bold(true)
 
 	modifiedStatusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("white")).
+		Foreground(d.theme.Text).
 		Bold(true)
 	deletedStatusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196")).
+		Foreground(d.theme.Danger).
 		Bold(true)
 	addedStatusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("34")).
+		Foreground(d.theme.Success).
 		Bold(true)
 
-	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("34")).Bold(true)
-	deletedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-	grayStatsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	addedStyle := lipgloss.NewStyle().Foreground(d.theme.Success).Bold(true)
+	deletedStyle := lipgloss.NewStyle().Foreground(d.theme.Danger).Bold(true)
+	grayStatsStyle := lipgloss.NewStyle().Foreground(d.theme.Subtle)
 
 	var fileList strings.Builder
 
 	title := titleStyle.Render(fmt.Sprintf("📄 %d Uncommitted File(s)", len(d.files)))
-	fileList.WriteString("  " + title + "\n")
+	fileList.WriteString("  " + title + d.hiddenNote() + "\n")
 
 	// Calculate max path width
 	maxPathWidth := d.width - 31
@@ -356,7 +840,9 @@ func (d *DashboardView) renderCompactFileList(maxFiles int) string {
 		file := d.files[i]
 
 		var statusStyle lipgloss.Style
-		if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
+		if file.IsIgnored {
+			statusStyle = lipgloss.NewStyle().Foreground(d.theme.Subtle)
+		} else if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
 			statusStyle = deletedStatusStyle
 		} else if file.IsUntracked || file.Status == models.StatusAdded || file.StagedStatus == models.StatusAdded {
 			statusStyle = addedStatusStyle
@@ -364,22 +850,22 @@ func (d *DashboardView) renderCompactFileList(maxFiles int) string {
 			statusStyle = modifiedStatusStyle
 		}
 
-		status := statusStyle.Render(file.DisplayStatus())
+		status := d.renderStatus(&file, statusStyle)
+		partial := d.partialMark(&file)
 
-		displayPath := file.Path
-		if len(displayPath) > maxPathWidth {
-			displayPath = "..." + displayPath[len(displayPath)-(maxPathWidth-3):]
-		}
+		displayPath := truncateLeft(file.Path, maxPathWidth-lipgloss.Width(partial))
 
 		var pathStyle lipgloss.Style
-		if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
-			pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		if file.IsIgnored {
+			pathStyle = lipgloss.NewStyle().Foreground(d.theme.Subtle)
+		} else if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
+			pathStyle = lipgloss.NewStyle().Foreground(d.theme.Danger)
 		} else if file.IsUntracked || file.Status == models.StatusAdded || file.StagedStatus == models.StatusAdded {
-			pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("34"))
+			pathStyle = lipgloss.NewStyle().Foreground(d.theme.Success)
 		} else {
-			pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("white"))
+			pathStyle = lipgloss.NewStyle().Foreground(d.theme.Text)
 		}
-		path := pathStyle.Render(displayPath)
+		path := pathStyle.Render(displayPath) + partial
 
 		var statsText string
 		if stats, ok := d.fileStats[file.Path]; ok {
@@ -406,7 +892,7 @@ func (d *DashboardView) renderCompactFileList(maxFiles int) string {
 
 	if len(d.files) > maxFiles {
 		remaining := len(d.files) - maxFiles
-		moreStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+		moreStyle := lipgloss.NewStyle().Foreground(d.theme.Subtle)
 		fileList.WriteString(moreStyle.Render(fmt.Sprintf("   ... and %d more file(s)\n", remaining)))
 	}
 
@@ -415,7 +901,7 @@ func (d *DashboardView) renderCompactFileList(maxFiles int) string {
 
 // renderCompactView renders the compact layout for 12-19 row terminals
 func (d *DashboardView) renderCompactView() string {
-	dividerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	dividerStyle := lipgloss.NewStyle().Foreground(d.theme.Subtle)
 	divider := dividerStyle.Render("  " + strings.Repeat("─", d.width-4))
 
 	// Build main content
@@ -429,8 +915,16 @@ func (d *DashboardView) renderCompactView() string {
 	parts = append(parts, d.renderCompactMetricsLine())
 	parts = append(parts, divider)
 
+	if d.emptyRepo {
+		onboardingStyle := lipgloss.NewStyle().Foreground(d.theme.Brand).Bold(true)
+		parts = append(parts, "  "+onboardingStyle.Render("✨ Empty repository — make your first commit"))
+	}
+
 	// File list (limited based on available height)
 	availableRows := d.height - 6 // branch, 2 dividers, metrics, footer
+	if d.emptyRepo {
+		availableRows-- // first-commit prompt
+	}
 	maxFiles := availableRows - 1 // account for title
 	if maxFiles < 1 {
 		maxFiles = 1
@@ -455,14 +949,13 @@ func (d *DashboardView) renderCompactView() string {
 	paddedContent := mainContent + strings.Repeat("\n", bottomPadding)
 
 	// Footer with hints and logo
-	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-	logoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	hintStyle := lipgloss.NewStyle().Foreground(d.theme.Muted)
 
-	hint := hintStyle.Render("n: new branch • c: commit • m: merge")
-	logo := logoStyle.Render("🧙 GitGoblin")
+	hint := hintStyle.Render(fmt.Sprintf("%s: new branch • %s: commit • m: merge", d.cfg.Keys.NewBranch.Help(), d.cfg.Keys.Commit.Help()))
+	logo := d.renderLogo()
 
 	hintLen := 38
-	logoLen := 12
+	logoLen := lipgloss.Width(logo)
 	spacing := d.width - hintLen - logoLen - 5
 	if spacing < 1 {
 		spacing = 1
@@ -475,14 +968,13 @@ func (d *DashboardView) renderCompactView() string {
 
 // renderUltraCompactView renders the densest format for <=11 row terminals
 func (d *DashboardView) renderUltraCompactView() string {
-	branchStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("cyan"))
-	repoStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("white"))
-	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("yellow")).Bold(true)
-	greenStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("34")).Bold(true)
-	redStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-	grayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-	logoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	branchStyle := lipgloss.NewStyle().Bold(true).Foreground(d.theme.Accent)
+	repoStyle := lipgloss.NewStyle().Bold(true).Foreground(d.theme.Text)
+	warningStyle := lipgloss.NewStyle().Foreground(d.theme.Highlight).Bold(true)
+	greenStyle := lipgloss.NewStyle().Foreground(d.theme.Success).Bold(true)
+	redStyle := lipgloss.NewStyle().Foreground(d.theme.Danger).Bold(true)
+	grayStyle := lipgloss.NewStyle().Foreground(d.theme.Subtle)
+	hintStyle := lipgloss.NewStyle().Foreground(d.theme.Muted)
 
 	var lines []string
 
@@ -491,7 +983,13 @@ func (d *DashboardView) renderUltraCompactView() string {
 	if d.repoName != "" {
 		headerParts = append(headerParts, repoStyle.Render(d.repoName))
 	}
-	headerParts = append(headerParts, fmt.Sprintf("🌿 %s", branchStyle.Render(d.branch)))
+	headerParts = append(headerParts, fmt.Sprintf("🌿 %s", branchStyle.Render(d.branchLabel())))
+	if op := d.operationText(); op != "" {
+		headerParts = append(headerParts, lipgloss.NewStyle().
+			Foreground(d.theme.Danger).
+			Bold(true).
+			Render("⚠ "+op))
+	}
 	if d.behindCount > 0 {
 		headerParts = append(headerParts, warningStyle.Render(fmt.Sprintf("⚠ ↓%d behind", d.behindCount)))
 	}
@@ -510,12 +1008,17 @@ func (d *DashboardView) renderUltraCompactView() string {
 		deletedText = grayStyle.Render("-0")
 	}
 
+	lineStats := fmt.Sprintf("%s/%s", addedText, deletedText)
+	if d.deltaStats {
+		lineStats = d.renderLineDelta()
+	}
+
 	metricsParts := []string{
-		fmt.Sprintf("📁 %d files", len(d.files)),
-		fmt.Sprintf("📊 %s/%s", addedText, deletedText),
+		"📁 " + d.fileCountText(),
+		fmt.Sprintf("📊 %s", lineStats),
 	}
-	if d.aheadCount > 0 {
-		metricsParts = append(metricsParts, fmt.Sprintf("⬆ %d ahead", d.aheadCount))
+	if ahead, _ := d.commitsAhead(); ahead > 0 {
+		metricsParts = append(metricsParts, fmt.Sprintf("⬆ %d ahead", ahead))
 	}
 	metricsParts = append(metricsParts, fmt.Sprintf("⏰ %s", d.formatTimeSinceCommit()))
 	lines = append(lines, "  "+strings.Join(metricsParts, "  "))
@@ -527,15 +1030,17 @@ func (d *DashboardView) renderUltraCompactView() string {
 			maxFiles = len(d.files)
 		}
 
-		modifiedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("white")).Bold(true)
-		deletedStatusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-		addedStatusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("34")).Bold(true)
+		modifiedStyle := lipgloss.NewStyle().Foreground(d.theme.Text).Bold(true)
+		deletedStatusStyle := lipgloss.NewStyle().Foreground(d.theme.Danger).Bold(true)
+		addedStatusStyle := lipgloss.NewStyle().Foreground(d.theme.Success).Bold(true)
 
 		for i := 0; i < maxFiles; i++ {
 			file := d.files[i]
 
 			var statusStyle lipgloss.Style
-			if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
+			if file.IsIgnored {
+				statusStyle = lipgloss.NewStyle().Foreground(d.theme.Subtle)
+			} else if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
 				statusStyle = deletedStatusStyle
 			} else if file.IsUntracked || file.Status == models.StatusAdded || file.StagedStatus == models.StatusAdded {
 				statusStyle = addedStatusStyle
@@ -543,28 +1048,28 @@ func (d *DashboardView) renderUltraCompactView() string {
 				statusStyle = modifiedStyle
 			}
 
-			status := statusStyle.Render(file.DisplayStatus())
+			status := d.renderStatus(&file, statusStyle)
+			partial := d.partialMark(&file)
 
 			// Truncate path if needed
-			displayPath := file.Path
 			maxPathWidth := d.width - 15
 			if maxPathWidth < 20 {
 				maxPathWidth = 20
 			}
-			if len(displayPath) > maxPathWidth {
-				displayPath = "..." + displayPath[len(displayPath)-(maxPathWidth-3):]
-			}
+			displayPath := truncateLeft(file.Path, maxPathWidth-lipgloss.Width(partial))
 
 			var pathStyle lipgloss.Style
-			if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
-				pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+			if file.IsIgnored {
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Subtle)
+			} else if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Danger)
 			} else if file.IsUntracked || file.Status == models.StatusAdded || file.StagedStatus == models.StatusAdded {
-				pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("34"))
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Success)
 			} else {
-				pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("white"))
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Text)
 			}
 
-			lines = append(lines, fmt.Sprintf("   %s  %s", status, pathStyle.Render(displayPath)))
+			lines = append(lines, fmt.Sprintf("   %s  %s%s", status, pathStyle.Render(displayPath), partial))
 		}
 
 		if len(d.files) > 2 {
@@ -585,11 +1090,11 @@ func (d *DashboardView) renderUltraCompactView() string {
 	paddedContent := mainContent + strings.Repeat("\n", bottomPadding)
 
 	// Footer with hints and logo
-	hint := hintStyle.Render("n: new • c: commit • m: merge")
-	logo := logoStyle.Render("🧙 GitGoblin")
+	hint := hintStyle.Render(fmt.Sprintf("%s: new • %s: commit • m: merge", d.cfg.Keys.NewBranch.Help(), d.cfg.Keys.Commit.Help()))
+	logo := d.renderLogo()
 
 	hintLen := 30
-	logoLen := 12
+	logoLen := lipgloss.Width(logo)
 	spacing := d.width - hintLen - logoLen - 5
 	if spacing < 1 {
 		spacing = 1
@@ -605,28 +1110,58 @@ func (d *DashboardView) renderNormalView() string {
 	// Branch as large ASCII art (top)
 	branchAscii := d.renderBranchAscii()
 
-	// Remote status (only if behind origin) - styled alert box
+	// Remote status (only if behind the remote) - styled alert box
 	var remoteStatus string
 	if d.behindCount > 0 {
 		warningTextStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("yellow")).
+			Foreground(d.theme.Highlight).
 			Bold(true)
 
 		warningBoxStyle := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("214")). // Orange border
-			Background(lipgloss.Color("58")).        // Subtle dark orange background
+			BorderForeground(d.theme.Warning). // Orange border
+			Background(d.theme.WarningBg).     // Subtle dark orange background
 			Padding(0, 2).
 			MarginBottom(1).
 			MarginLeft(5)
 
-		warningText := warningTextStyle.Render(fmt.Sprintf("⚠  Behind origin: ↓%d", d.behindCount))
+		warningText := warningTextStyle.Render(fmt.Sprintf("⚠  Behind %s: ↓%d", d.remote, d.behindCount))
 		remoteStatus = warningBoxStyle.Render(warningText)
 	}
 
+	// In-progress operation banner (merge/rebase/cherry-pick), or a
+	// detached HEAD warning when nothing is in progress
+	operationBanner := d.renderOperationBanner()
+	if operationBanner == "" {
+		operationBanner = d.renderDetachedBanner()
+	}
+
 	// Status box with metrics
 	statusBox := d.renderStatusBox()
 
+	// First-commit prompt in a repository without commits
+	onboarding := d.renderOnboarding()
+
+	// Create subtle divider
+	dividerStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Subtle).
+		MarginLeft(5)
+	divider := dividerStyle.Render("─────────────────────────────────────────")
+
+	// Build top section (branch + remote + status box)
+	sections := []string{branchAscii, ""}
+	if operationBanner != "" {
+		sections = append(sections, operationBanner, "")
+	}
+	if remoteStatus != "" {
+		sections = append(sections, remoteStatus, "")
+	}
+	if onboarding != "" {
+		sections = append(sections, onboarding, "")
+	}
+	sections = append(sections, statusBox, "", divider)
+	topSection := lipgloss.JoinVertical(lipgloss.Left, sections...)
+
 	// Main content area
 	var content string
 	if len(d.files) == 0 {
@@ -635,33 +1170,33 @@ func (d *DashboardView) renderNormalView() string {
 	} else {
 		// Dirty state - full width file list, ALL files
 		titleStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("cyan")).
+			Foreground(d.theme.Accent).
 			Bold(true)
 
 		// Define status styles with proper colors
 		modifiedStatusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("white")).
+			Foreground(d.theme.Text).
 			Bold(true)
 
 		deletedStatusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
+			Foreground(d.theme.Danger).
 			Bold(true)
 
 		addedStatusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("34")).
+			Foreground(d.theme.Success).
 			Bold(true)
 
 		// Styles for line stats
-		addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("34")).Bold(true)
-		deletedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-		grayStatsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+		addedStyle := lipgloss.NewStyle().Foreground(d.theme.Success).Bold(true)
+		deletedStyle := lipgloss.NewStyle().Foreground(d.theme.Danger).Bold(true)
+		grayStatsStyle := lipgloss.NewStyle().Foreground(d.theme.Subtle)
 
 		// Build file list content
 		var fileList strings.Builder
 
 		// Add title
 		title := titleStyle.Render(fmt.Sprintf("📄 %d Uncommitted File(s)", len(d.files)))
-		fileList.WriteString(title + "\n\n")
+		fileList.WriteString(title + d.hiddenNote() + "\n\n")
 
 		// Calculate max path width (terminal width - margin - status - spacing - stats)
 		// Format: " MM  path (+999/-999)\n"
@@ -671,11 +1206,55 @@ func (d *DashboardView) renderNormalView() string {
 			maxPathWidth = 20 // Minimum readable width
 		}
 
-		// Show ALL files (no limit)
-		for _, file := range d.files {
+		// Lay the list out as rows, a header per section, so only the rows
+		// that fit on screen get rendered
+		type fileRow struct {
+			title string             // section header, with file nil
+			file  *models.FileChange // nil for headers and the gaps between sections
+		}
+		var rows []fileRow
+		for i, group := range models.GroupFiles(d.files) {
+			if i > 0 {
+				rows = append(rows, fileRow{})
+			}
+			rows = append(rows, fileRow{title: fmt.Sprintf("%s (%d)", group.Title, len(group.Files))})
+			for j := range group.Files {
+				rows = append(rows, fileRow{file: &group.Files[j]})
+			}
+		}
+
+		// Whatever the top section, title and bottom line leave free, with
+		// one row kept for the scroll line when not everything fits
+		visible := len(rows)
+		budget := d.height - 2 - (strings.Count(topSection, "\n") + 1) - 3
+		if budget < 3 {
+			budget = 3
+		}
+		if len(rows) > budget {
+			visible = budget - 1
+		}
+		d.maxFileOffset = len(rows) - visible
+		offset := max(0, min(d.fileOffset, d.maxFileOffset))
+
+		groupStyle := lipgloss.NewStyle().
+			Foreground(d.theme.Secondary).
+			Bold(true)
+
+		for _, row := range rows[offset : offset+visible] {
+			if row.file == nil {
+				if row.title != "" {
+					fileList.WriteString(groupStyle.Render(row.title))
+				}
+				fileList.WriteString("\n")
+				continue
+			}
+			file := row.file
+
 			// Determine status color based on file state
 			var statusStyle lipgloss.Style
-			if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
+			if file.IsIgnored {
+				statusStyle = lipgloss.NewStyle().Foreground(d.theme.Subtle)
+			} else if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
 				statusStyle = deletedStatusStyle
 			} else if file.IsUntracked || file.Status == models.StatusAdded || file.StagedStatus == models.StatusAdded {
 				statusStyle = addedStatusStyle
@@ -684,25 +1263,25 @@ func (d *DashboardView) renderNormalView() string {
 				statusStyle = modifiedStatusStyle
 			}
 
-			status := statusStyle.Render(file.DisplayStatus())
+			status := d.renderStatus(file, statusStyle)
+			partial := d.partialMark(file)
 
 			// Truncate path from left if too long
-			displayPath := file.Path
-			if len(displayPath) > maxPathWidth {
-				// Keep the end of the path (filename is most important)
-				displayPath = "..." + displayPath[len(displayPath)-(maxPathWidth-3):]
-			}
+			// Keep the end of the path (filename is most important)
+			displayPath := truncateLeft(file.Path, maxPathWidth-lipgloss.Width(partial))
 
 			// Apply same color to path as status
 			var pathStyle lipgloss.Style
-			if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
-				pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+			if file.IsIgnored {
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Subtle)
+			} else if file.Status == models.StatusDeleted || file.StagedStatus == models.StatusDeleted {
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Danger)
 			} else if file.IsUntracked || file.Status == models.StatusAdded || file.StagedStatus == models.StatusAdded {
-				pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("34"))
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Success)
 			} else {
-				pathStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("white"))
+				pathStyle = lipgloss.NewStyle().Foreground(d.theme.Text)
 			}
-			path := pathStyle.Render(displayPath)
+			path := pathStyle.Render(displayPath) + partial
 
 			// Get line stats for this file
 			var statsText string
@@ -731,6 +1310,28 @@ func (d *DashboardView) renderNormalView() string {
 			fileList.WriteString(fmt.Sprintf(" %s  %s%s\n", status, path, statsText))
 		}
 
+		// Say how much is off screen, the title keeps the full count
+		if visible < len(rows) {
+			above, below := 0, 0
+			for i, row := range rows {
+				if row.file != nil && i < offset {
+					above++
+				} else if row.file != nil && i >= offset+visible {
+					below++
+				}
+			}
+			var parts []string
+			if above > 0 {
+				parts = append(parts, fmt.Sprintf("↑ %d above", above))
+			}
+			if below > 0 {
+				parts = append(parts, fmt.Sprintf("+%d more", below))
+			}
+			parts = append(parts, d.cfg.Keys.Down.Help()+"/"+d.cfg.Keys.Up.Help()+": scroll")
+			moreStyle := lipgloss.NewStyle().Foreground(d.theme.Subtle)
+			fileList.WriteString(moreStyle.Render(" "+strings.Join(parts, " • ")) + "\n")
+		}
+
 		// Apply left margin to entire file list
 		fileListStyle := lipgloss.NewStyle().
 			MarginLeft(5).
@@ -739,24 +1340,8 @@ func (d *DashboardView) renderNormalView() string {
 		content = fileListStyle.Render(strings.TrimRight(fileList.String(), "\n"))
 	}
 
-	// Create subtle divider
-	dividerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		MarginLeft(5)
-	divider := dividerStyle.Render("─────────────────────────────────────────")
-
-	// Build top section (branch + remote + status box)
-	var topSection string
-	if remoteStatus != "" {
-		topSection = lipgloss.JoinVertical(lipgloss.Left, branchAscii, "", remoteStatus, "", statusBox, "", divider)
-	} else {
-		topSection = lipgloss.JoinVertical(lipgloss.Left, branchAscii, "", statusBox, "", divider)
-	}
-
 	// Logo in bottom right
-	logo := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("170")).
-		Render("🧙 GitGoblin")
+	logo := d.renderLogo()
 
 	// Combine everything
 	mainContent := lipgloss.JoinVertical(lipgloss.Left, topSection, content)
@@ -772,12 +1357,12 @@ func (d *DashboardView) renderNormalView() string {
 	paddedContent := mainContent + strings.Repeat("\n", bottomPadding)
 
 	// Add hint on left, logo on right
-	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-	hint := hintStyle.Render("n: new branch • c: commit • m: merge")
+	hintStyle := lipgloss.NewStyle().Foreground(d.theme.Muted)
+	hint := hintStyle.Render(fmt.Sprintf("%s: new branch • %s: commit • m: merge", d.cfg.Keys.NewBranch.Help(), d.cfg.Keys.Commit.Help()))
 
 	// Calculate spacing between hint and logo
 	hintLen := 38 // "n: new branch • c: commit • m: merge"
-	logoLen := 12 // "🧙 GitGoblin"
+	logoLen := lipgloss.Width(logo)
 	spacing := d.width - hintLen - logoLen - 5
 	if spacing < 1 {
 		spacing = 1
@@ -809,54 +1394,122 @@ func (d *DashboardView) View() string {
 func (d *DashboardView) renderBranchAscii() string {
 	branchStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("cyan"))
+		Foreground(d.theme.Accent)
 
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("cyan")).
+		BorderForeground(d.theme.Accent).
 		Padding(0, 2).
 		MarginTop(1).
 		MarginBottom(1).
 		MarginLeft(5)
 
+	// Space left for the name: margin (5) + border (2) + padding (4) + icon (3)
+	label := d.branchLabel()
+	maxLabelWidth := d.width - 14
+	if maxLabelWidth < 10 {
+		maxLabelWidth = 10
+	}
+
+	if lipgloss.Width(label) > maxLabelWidth {
+		if d.wrapBranch {
+			// Wrap inside the box, keeping wrapped lines aligned after the icon
+			wrapped := lipgloss.NewStyle().Width(maxLabelWidth).Render(label)
+			label = strings.ReplaceAll(wrapped, "\n", "\n   ")
+		} else {
+			label = truncateMiddle(label, maxLabelWidth)
+		}
+	}
+
 	// Add branch icon
-	branchText := fmt.Sprintf("🌿 %s", d.branch)
+	branchText := fmt.Sprintf("🌿 %s", label)
 	return boxStyle.Render(branchStyle.Render(branchText))
 }
 
-// parseUpstream extracts ahead/behind counts from upstream string
-// e.g., "origin/main: ahead 2" or "origin/main: ahead 2, behind 1"
-func parseUpstream(upstream string) (ahead, behind int) {
-	ahead, behind = 0, 0
-
-	// Format: "origin/main: ahead 2, behind 1"
-	if !strings.Contains(upstream, ":") {
-		return
+// renderDetachedBanner warns that HEAD is detached, e.g. after checking out
+// a commit from the graph, empty when it is on a branch
+func (d *DashboardView) renderDetachedBanner() string {
+	if !d.detached {
+		return ""
 	}
 
-	parts := strings.Split(upstream, ":")
-	if len(parts) < 2 {
-		return
+	titleStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Warning).
+		Bold(true)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Muted)
+
+	bannerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.theme.Warning).
+		Padding(0, 2).
+		MarginBottom(1).
+		MarginLeft(5)
+
+	title := "⚠  HEAD DETACHED AT " + d.branch
+	hint := "Commits made here are on no branch. Press b to switch to a branch, or l then b to create one here"
+	return bannerStyle.Render(titleStyle.Render(title) + "\n" + hintStyle.Render(hint))
+}
+
+// renderOnboarding renders the first-commit prompt shown while the
+// repository has no commits, empty otherwise
+func (d *DashboardView) renderOnboarding() string {
+	if !d.emptyRepo {
+		return ""
 	}
 
-	status := parts[1]
+	titleStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Brand).
+		Bold(true)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Muted)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.theme.Brand).
+		Padding(0, 2).
+		MarginBottom(1).
+		MarginLeft(5)
+
+	hint := fmt.Sprintf("Press %s to stage files and make your first commit", d.cfg.Keys.Commit.Help())
+	return boxStyle.Render(titleStyle.Render("✨ Empty repository — make your first commit") + "\n" + hintStyle.Render(hint))
+}
 
-	// Parse "ahead X"
-	if strings.Contains(status, "ahead") {
-		fmt.Sscanf(status, "%*s%d", &ahead)
+// renderOperationBanner renders an alert box while a merge, rebase,
+// cherry-pick or revert is in progress, including where HEAD currently
+// points and how to carry on
+func (d *DashboardView) renderOperationBanner() string {
+	text := d.operationText()
+	if text == "" {
+		return ""
 	}
 
-	// Parse "behind X"
-	if strings.Contains(status, "behind") {
-		var dummy int
-		if strings.Contains(status, "ahead") {
-			// Format: "ahead 2, behind 1"
-			fmt.Sscanf(status, "%*s%d%*s%*s%d", &dummy, &behind)
-		} else {
-			// Format: "behind 1"
-			fmt.Sscanf(status, "%*s%d", &behind)
-		}
+	titleStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Danger).
+		Bold(true)
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Text)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(d.theme.Muted)
+
+	bannerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.theme.Danger).
+		Padding(0, 2).
+		MarginBottom(1).
+		MarginLeft(5)
+
+	content := titleStyle.Render("⚠  " + text)
+	if detail := d.operationDetail(); detail != "" {
+		content += "\n" + detailStyle.Render(detail)
+	}
+	if hint := d.operationHint(); hint != "" {
+		content += "\n" + hintStyle.Render(hint)
 	}
 
-	return
+	return bannerStyle.Render(content)
 }
@@ -0,0 +1,314 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// minSplitDiffWidth is the narrowest terminal that gets side-by-side diffs
+const minSplitDiffWidth = 100
+
+// splitRow is one aligned row of a side-by-side diff. Kind is ' ' for
+// context, '-'/'+' for changed lines, '@' for hunk headers and 0 for an
+// empty cell where the other side has no counterpart.
+type splitRow struct {
+	left, right         string
+	leftKind, rightKind byte
+}
+
+// parseSplitRows turns a unified diff into rows with the old content on the
+// left and the new content on the right. Runs of removed and added lines
+// within a hunk are paired up line by line.
+func parseSplitRows(diff string) []splitRow {
+	var rows []splitRow
+	var removed, added []string
+
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			row := splitRow{}
+			if i < len(removed) {
+				row.left, row.leftKind = removed[i], '-'
+			}
+			if i < len(added) {
+				row.right, row.rightKind = added[i], '+'
+			}
+			rows = append(rows, row)
+		}
+		removed, added = nil, nil
+	}
+
+	inHunk := false
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			flush()
+			inHunk = true
+			rows = append(rows, splitRow{left: line, leftKind: '@'})
+		case !inHunk:
+			// File header lines (diff --git, index, ---, +++)
+			continue
+		case strings.HasPrefix(line, "-"):
+			removed = append(removed, line[1:])
+		case strings.HasPrefix(line, "+"):
+			added = append(added, line[1:])
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file"
+			continue
+		default:
+			flush()
+			text := strings.TrimPrefix(line, " ")
+			rows = append(rows, splitRow{left: text, leftKind: ' ', right: text, rightKind: ' '})
+		}
+	}
+	flush()
+
+	return rows
+}
+
+// renderSplitDiff renders a unified diff side by side in the given width,
+// showing at most maxRows rows
+func renderSplitDiff(t *Theme, diff string, width, maxRows int) string {
+	rows := parseSplitRows(diff)
+	truncated := false
+	if len(rows) > maxRows {
+		rows = rows[:maxRows]
+		truncated = true
+	}
+
+	headerStyle := lipgloss.NewStyle().Foreground(t.Accent)
+	separatorStyle := lipgloss.NewStyle().Foreground(t.Border)
+
+	colWidth := (width - 3) / 2
+	var lines []string
+	for _, row := range rows {
+		if row.leftKind == '@' {
+			lines = append(lines, headerStyle.Render(ansi.Truncate(row.left, width, "…")))
+			continue
+		}
+		left := renderSplitCell(t, row.left, row.leftKind, colWidth)
+		right := renderSplitCell(t, row.right, row.rightKind, colWidth)
+		lines = append(lines, left+separatorStyle.Render(" │ ")+right)
+	}
+
+	if truncated {
+		lines = append(lines, "... (truncated)")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderSplitCell renders one side of a split row padded to width
+func renderSplitCell(t *Theme, text string, kind byte, width int) string {
+	style := lipgloss.NewStyle().Foreground(t.Text)
+	prefix := "  "
+	switch kind {
+	case '-':
+		style = lipgloss.NewStyle().Foreground(t.Danger)
+		prefix = "- "
+	case '+':
+		style = lipgloss.NewStyle().Foreground(t.Success)
+		prefix = "+ "
+	case 0:
+		prefix = ""
+	}
+
+	text = ansi.Truncate(prefix+expandTabs(text), width, "…")
+	padding := width - lipgloss.Width(text)
+	if padding < 0 {
+		padding = 0
+	}
+	return style.Render(text) + strings.Repeat(" ", padding)
+}
+
+// expandTabs replaces tabs so column widths can be measured
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
+
+// maxWordDiffCells bounds the token comparison per line pair so huge
+// minified lines don't stall rendering
+const maxWordDiffCells = 40000
+
+// colorizeDiff colors a unified diff line by line: additions green,
+// removals red, hunk headers cyan, context dimmed and file headers bold. With wordDiff,
+// paired removed/added lines also highlight just the words that changed.
+func colorizeDiff(t *Theme, diff string, wordDiff bool) string {
+	addStyle := lipgloss.NewStyle().Foreground(t.Success)
+	delStyle := lipgloss.NewStyle().Foreground(t.Danger)
+	hunkStyle := lipgloss.NewStyle().Foreground(t.Accent)
+	fileStyle := lipgloss.NewStyle().Foreground(t.Text).Bold(true)
+	metaStyle := lipgloss.NewStyle().Foreground(t.Muted)
+	contextStyle := lipgloss.NewStyle().Foreground(t.Text).Faint(true)
+
+	var out []string
+	var removed, added []string
+
+	flush := func() {
+		for i, line := range removed {
+			if wordDiff && i < len(added) {
+				oldLine, newLine := renderWordDiff(t, line, added[i])
+				out = append(out, oldLine)
+				added[i] = newLine
+				continue
+			}
+			out = append(out, delStyle.Render("-"+line))
+		}
+		for i, line := range added {
+			if wordDiff && i < len(removed) {
+				out = append(out, line) // already rendered above
+				continue
+			}
+			out = append(out, addStyle.Render("+"+line))
+		}
+		removed, added = nil, nil
+	}
+
+	inHunk := false
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		line = expandTabs(strings.TrimSuffix(line, "\r"))
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			inHunk = false
+			out = append(out, fileStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			flush()
+			inHunk = true
+			out = append(out, hunkStyle.Render(line))
+		case !inHunk:
+			// index, ---/+++, new file, rename and similar header lines
+			if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+				out = append(out, fileStyle.Render(line))
+			} else {
+				out = append(out, metaStyle.Render(line))
+			}
+		case strings.HasPrefix(line, "-"):
+			if len(added) > 0 {
+				flush()
+			}
+			removed = append(removed, line[1:])
+		case strings.HasPrefix(line, "+"):
+			added = append(added, line[1:])
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file" belongs to the line before it
+			flush()
+			out = append(out, metaStyle.Render(line))
+		default:
+			flush()
+			out = append(out, contextStyle.Render(line))
+		}
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+// renderWordDiff renders a removed/added line pair, emphasizing only the
+// tokens that differ between them
+func renderWordDiff(t *Theme, oldLine, newLine string) (string, string) {
+	delStyle := lipgloss.NewStyle().Foreground(t.Danger)
+	addStyle := lipgloss.NewStyle().Foreground(t.Success)
+	delEmphasis := delStyle.Background(t.DangerBg).Bold(true)
+	addEmphasis := addStyle.Background(t.SuccessBg).Bold(true)
+
+	a, b := tokenize(oldLine), tokenize(newLine)
+	if len(a)*len(b) > maxWordDiffCells {
+		return delStyle.Render("-" + oldLine), addStyle.Render("+" + newLine)
+	}
+
+	keepA, keepB := commonTokens(a, b)
+	return renderTokens("-", a, keepA, delStyle, delEmphasis),
+		renderTokens("+", b, keepB, addStyle, addEmphasis)
+}
+
+// renderTokens joins tokens, grouping runs of kept and changed tokens so
+// each run is styled once
+func renderTokens(prefix string, tokens []string, keep []bool, plain, emphasis lipgloss.Style) string {
+	var b strings.Builder
+	b.WriteString(plain.Render(prefix))
+
+	for i := 0; i < len(tokens); {
+		j := i
+		for j < len(tokens) && keep[j] == keep[i] {
+			j++
+		}
+		run := strings.Join(tokens[i:j], "")
+		if keep[i] {
+			b.WriteString(plain.Render(run))
+		} else {
+			b.WriteString(emphasis.Render(run))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// tokenize splits a line into words, runs of whitespace and single
+// punctuation characters
+func tokenize(s string) []string {
+	var tokens []string
+	runes := []rune(s)
+
+	class := func(r rune) int {
+		switch {
+		case r == ' ' || r == '\t':
+			return 1
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return 2
+		}
+		return 3
+	}
+
+	for i := 0; i < len(runes); {
+		c := class(runes[i])
+		j := i + 1
+		if c != 3 {
+			for j < len(runes) && class(runes[j]) == c {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+// commonTokens marks the tokens of a and b that are part of their longest
+// common subsequence
+func commonTokens(a, b []string) (keepA, keepB []bool) {
+	keepA = make([]bool, len(a))
+	keepB = make([]bool, len(b))
+
+	// lengths[i][j] is the LCS length of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			keepA[i], keepB[j] = true, true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return keepA, keepB
+}
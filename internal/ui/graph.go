@@ -3,33 +3,149 @@ package ui
 import (
 	"fmt"
 	"strings"
-	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
 	"github.com/Johannes-Berggren/GitGoblin/internal/git"
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
 
 type GraphView struct {
-	commits    []models.Commit
-	graphLines []string
-	cursor     int
-	offset     int
-	height     int
-	width      int
+	theme       *Theme
+	commits     []models.Commit
+	graphLines  []string // graph drawn left of each commit
+	loaded      bool
+	hasMore     bool // another page of commits can be loaded
+	loadingMore bool
+	loader      loader
+	cursor      int
+	offset      int
+	height      int
+	width       int
+	fullHashes  bool
+	initials    bool // show authors' initials rather than their names
+	sign        bool // sign commits made from the commit details
+	keys        config.KeyMap
+	author      string // active author filter, empty for all authors
+	since       string // active date range, empty for the default range
+	until       string
+	search      string // active search, empty when showing every commit
+	matches     []int  // indices into commits that match the search
+	filterInput textinput.Model
+	filtering   graphFilterField // field being prompted for
+	pendingFrom string           // since value entered before prompting for until
+	filterErr   error
+	detail      *CommitDetailView // open commit detail, nil when showing the graph
+	picking     bool              // enter and esc report a commitPickedMsg instead
+	path        string            // show only the history of this file
+	scope       *graphScope       // shared with the app, see graphScope
+	restore     string            // hash to select once the first page loads
+	err         error
 }
 
-func NewGraphView() *GraphView {
+type graphFilterField int
+
+const (
+	filterNone graphFilterField = iota
+	filterAuthor
+	filterSince
+	filterUntil
+	filterSearch
+)
+
+func NewGraphView(cfg *config.Config, theme *Theme) *GraphView {
+	ti := textinput.New()
+	ti.CharLimit = 100
+	ti.Width = 40
+
 	return &GraphView{
-		cursor: 0,
-		offset: 0,
+		theme:       theme,
+		loader:      newLoader(theme),
+		cursor:      0,
+		offset:      0,
+		fullHashes:  cfg.FullHashes,
+		initials:    cfg.AuthorInitials,
+		sign:        cfg.SignCommits,
+		keys:        cfg.Keys,
+		filterInput: ti,
+		scope:       &graphScope{},
 	}
 }
 
+// graphScope is which refs the graph is drawn from. The app keeps one for
+// the session, so the graph reopens the way it was left.
+type graphScope struct {
+	allRefs bool // every branch and tag instead of the current branch only
+	remotes bool // with allRefs, remote-tracking branches too
+}
+
+// describe names the scope for the graph's header
+func (s graphScope) describe() string {
+	switch {
+	case s.allRefs && s.remotes:
+		return "all branches"
+	case s.allRefs:
+		return "local branches"
+	}
+	return "current branch"
+}
+
+type graphCloseMsg struct{}
+
+// commitPickedMsg reports the commit chosen in a graph opened for picking,
+// hash is empty when the user backed out
+type commitPickedMsg struct {
+	hash string
+}
+
+// branchAtCommitRequestMsg asks the app to prompt for the name of a branch
+// to create at a commit picked in the graph
+type branchAtCommitRequestMsg struct {
+	hash  string
+	short string
+}
+
+// commitCheckedOutMsg reports the result of checking out a commit from the
+// graph as a detached HEAD
+type commitCheckedOutMsg struct {
+	hash string
+	err  error
+}
+
+// cherryPickedMsg reports the result of cherry-picking a commit from the
+// graph onto the current branch
+type cherryPickedMsg struct {
+	hash string
+	err  error
+}
+
+// revertedMsg reports the result of reverting a commit from the graph on
+// the current branch
+type revertedMsg struct {
+	hash string
+	err  error
+}
+
+// graphPageSize is how many commits the graph loads at a time
+const graphPageSize = 100
+
+// graphPrefetch is how close the cursor gets to the last loaded commit
+// before the next page is requested
+const graphPrefetch = 10
+
+// commitsLoadedMsg carries a page of commits, starting at skip, loaded with
+// opts. Pages for filters that have since changed are dropped.
 type commitsLoadedMsg struct {
 	commits    []models.Commit
 	graphLines []string
+	more       bool
+	opts       git.LogOptions
+	skip       int
 }
 
 func (g *GraphView) Init() tea.Cmd {
@@ -37,33 +153,112 @@ func (g *GraphView) Init() tea.Cmd {
 }
 
 func (g *GraphView) loadCommits() tea.Cmd {
+	g.loadingMore = false
+	return g.loader.start(loadCommitPage(g.logOptions(), 0))
+}
+
+// logOptions returns the git log filters currently applied
+func (g *GraphView) logOptions() git.LogOptions {
+	return git.LogOptions{
+		Author: g.author,
+		Since:  g.since,
+		Until:  g.until,
+		Path:   g.path,
+
+		AllRefs: g.scope.allRefs,
+		Remotes: g.scope.remotes,
+	}
+}
+
+func loadCommitPage(opts git.LogOptions, skip int) tea.Cmd {
 	return func() tea.Msg {
-		commits, graphLines, err := git.GetCommits(100)
+		commits, graphLines, more, err := git.GetCommitsPaged(opts, skip, graphPageSize)
 		if err != nil {
 			return errMsg{err}
 		}
-		return commitsLoadedMsg{commits, graphLines}
+		return commitsLoadedMsg{commits, graphLines, more, opts, skip}
 	}
 }
 
+// loadMoreIfNeeded requests the next page once the cursor nears the last
+// loaded commit
+func (g *GraphView) loadMoreIfNeeded() tea.Cmd {
+	if !g.hasMore || g.loadingMore || g.rowCount() == 0 {
+		return nil
+	}
+	if g.commitIndex(g.cursor) < len(g.commits)-graphPrefetch {
+		return nil
+	}
+	g.loadingMore = true
+	return g.loader.start(loadCommitPage(g.logOptions(), len(g.commits)))
+}
+
 func (g *GraphView) Update(msg tea.Msg) (*GraphView, tea.Cmd) {
+	if g.detail != nil {
+		return g.updateDetail(msg)
+	}
+
 	switch msg := msg.(type) {
 	case commitsLoadedMsg:
-		g.commits = msg.commits
-		g.graphLines = msg.graphLines
+		if msg.opts != g.logOptions() {
+			break
+		}
+		if msg.skip == 0 {
+			g.commits = msg.commits
+			g.graphLines = msg.graphLines
+			g.restoreSelection()
+		} else if g.loadingMore && msg.skip == len(g.commits) {
+			g.commits = append(g.commits, msg.commits...)
+			g.graphLines = append(g.graphLines, msg.graphLines...)
+			g.loadingMore = false
+		} else {
+			break
+		}
+		g.hasMore = msg.more
+		g.loaded = true
+		g.loader.stop()
+		g.err = nil
+		g.matches = g.findMatches(g.search)
+
+	case errMsg:
+		g.err = msg.err
+		g.loaded = true
+		g.loadingMore = false
+		g.loader.stop()
+
+	case spinner.TickMsg:
+		return g, g.loader.tick(msg)
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "j", "down":
-			if g.cursor < len(g.commits)-1 {
+		if g.filtering != filterNone {
+			return g, g.updateFilterInput(msg)
+		}
+
+		switch key := msg.String(); {
+		case key == "esc" && g.search != "":
+			g.clearSearch()
+
+		case key == "esc" && g.filtered():
+			// Clear the author and date filters before leaving
+			g.author, g.since, g.until = "", "", ""
+			g.cursor = 0
+			g.offset = 0
+			return g, g.loadCommits()
+
+		case key == "esc" && g.picking:
+			return g, func() tea.Msg { return commitPickedMsg{} }
+
+		case key == "esc":
+			return g, func() tea.Msg { return graphCloseMsg{} }
+
+		case g.keys.Down.Matches(key):
+			if g.cursor < g.rowCount()-1 {
 				g.cursor++
 				// Auto-scroll down
-				if g.cursor >= g.offset+g.height-5 {
-					g.offset++
-				}
+				g.scrollToCursor()
 			}
 
-		case "k", "up":
+		case g.keys.Up.Matches(key):
 			if g.cursor > 0 {
 				g.cursor--
 				// Auto-scroll up
@@ -72,18 +267,108 @@ func (g *GraphView) Update(msg tea.Msg) (*GraphView, tea.Cmd) {
 				}
 			}
 
-		case "g":
+		case g.keys.Top.Matches(key):
 			// Go to top
 			g.cursor = 0
 			g.offset = 0
 
-		case "G":
+		case g.keys.Bottom.Matches(key):
 			// Go to bottom
-			g.cursor = len(g.commits) - 1
-			if g.cursor > g.height-5 {
-				g.offset = g.cursor - g.height + 5
+			g.cursor = g.rowCount() - 1
+			g.scrollToCursor()
+
+		case key == "#":
+			// Toggle full/short hashes
+			g.fullHashes = !g.fullHashes
+
+		case key == "a":
+			// Filter by author
+			return g, g.openFilter(filterAuthor, g.author)
+
+		case key == "A" && g.path == "":
+			// Toggle between every branch and the current one only
+			g.scope.allRefs = !g.scope.allRefs
+			return g, g.reloadScope()
+
+		case key == "R" && g.path == "" && g.scope.allRefs:
+			// Toggle remote-tracking branches
+			g.scope.remotes = !g.scope.remotes
+			return g, g.reloadScope()
+
+		case key == "D":
+			// Filter by date range, since then until
+			return g, g.openFilter(filterSince, g.since)
+
+		case key == "/":
+			// Search the loaded commits
+			return g, g.openFilter(filterSearch, g.search)
+
+		case key == "n" && g.search != "":
+			// Next match, wrapping around
+			if rows := g.rowCount(); rows > 0 {
+				g.cursor = (g.cursor + 1) % rows
+				g.scrollToCursor()
+			}
+
+		case key == "N" && g.search != "":
+			// Previous match, wrapping around
+			if rows := g.rowCount(); rows > 0 {
+				g.cursor = (g.cursor - 1 + rows) % rows
+				g.scrollToCursor()
+			}
+
+		case key == "c" && !g.picking:
+			// Cherry-pick the selected commit onto the current branch
+			if commit := g.SelectedCommit(); commit != nil {
+				hash, short := commit.Hash, commit.ShortHash
+				prompt := fmt.Sprintf("Cherry-pick %s %q onto the current branch?", short, commit.Message)
+				return g, requestConfirm(prompt, func() tea.Msg {
+					return cherryPickedMsg{hash: short, err: git.CherryPick(hash)}
+				}, nil)
+			}
+
+		case key == "v" && !g.picking:
+			// Undo the selected commit with a new commit on the current branch
+			if commit := g.SelectedCommit(); commit != nil {
+				hash, short := commit.Hash, commit.ShortHash
+				prompt := fmt.Sprintf("Revert %s %q with a new commit on the current branch?", short, commit.Message)
+				return g, requestConfirm(prompt, func() tea.Msg {
+					return revertedMsg{hash: short, err: git.Revert(hash)}
+				}, nil)
+			}
+
+		case key == "b" && !g.picking && g.path == "":
+			// Create a branch at the selected commit
+			if commit := g.SelectedCommit(); commit != nil {
+				hash, short := commit.Hash, commit.ShortHash
+				return g, func() tea.Msg { return branchAtCommitRequestMsg{hash: hash, short: short} }
+			}
+
+		case key == "o" && !g.picking && g.path == "":
+			// Check out the selected commit, detaching HEAD
+			if commit := g.SelectedCommit(); commit != nil {
+				hash, short := commit.Hash, commit.ShortHash
+				prompt := fmt.Sprintf("Check out %s %q? HEAD will be detached, switch back to a branch to keep committing on one.", short, commit.Message)
+				return g, requestConfirm(prompt, func() tea.Msg {
+					return commitCheckedOutMsg{hash: short, err: git.CheckoutCommit(hash)}
+				}, nil)
+			}
+
+		case key == "enter" && g.picking:
+			if commit := g.SelectedCommit(); commit != nil {
+				hash := commit.Hash
+				return g, func() tea.Msg { return commitPickedMsg{hash} }
+			}
+
+		case key == "enter":
+			// Show the selected commit in full
+			if commit := g.SelectedCommit(); commit != nil {
+				g.detail = NewCommitDetailView(commit.Hash, g.fullHashes, g.sign, g.keys, g.theme)
+				g.detail, _ = g.detail.Update(tea.WindowSizeMsg{Width: g.width, Height: g.height})
+				return g, g.detail.Init()
 			}
 		}
+		return g, g.loadMoreIfNeeded()
 
 	case tea.WindowSizeMsg:
 		g.width = msg.Width
@@ -93,69 +378,246 @@ func (g *GraphView) Update(msg tea.Msg) (*GraphView, tea.Cmd) {
 	return g, nil
 }
 
+// updateDetail forwards messages to the open commit detail view
+func (g *GraphView) updateDetail(msg tea.Msg) (*GraphView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case commitDetailCloseMsg:
+		g.detail = nil
+		return g, nil
+
+	case tea.WindowSizeMsg:
+		g.width = msg.Width
+		g.height = msg.Height
+	}
+
+	var cmd tea.Cmd
+	g.detail, cmd = g.detail.Update(msg)
+	return g, cmd
+}
+
+// openFilter prompts for a filter field, starting from its current value
+func (g *GraphView) openFilter(field graphFilterField, value string) tea.Cmd {
+	switch field {
+	case filterAuthor:
+		g.filterInput.Prompt = "Author: "
+		g.filterInput.Placeholder = "name or email"
+	case filterSince:
+		g.filterInput.Prompt = "Since: "
+		g.filterInput.Placeholder = "2 weeks ago, 2024-01-31, ... (empty for no lower bound)"
+	case filterUntil:
+		g.filterInput.Prompt = "Until: "
+		g.filterInput.Placeholder = "yesterday, 2024-02-14, ... (empty for no upper bound)"
+	case filterSearch:
+		g.filterInput.Prompt = "/"
+		g.filterInput.Placeholder = "message, author or hash"
+	}
+	g.filtering = field
+	g.filterErr = nil
+	g.filterInput.SetValue(value)
+	g.filterInput.CursorEnd()
+	return g.filterInput.Focus()
+}
+
+// typing reports whether keys go to a text prompt, the filter's or the one
+// for amending an author in the commit details
+func (g *GraphView) typing() bool {
+	return g.filtering != filterNone || (g.detail != nil && g.detail.editing)
+}
+
+// updateFilterInput handles keys while a filter prompt is open
+func (g *GraphView) updateFilterInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		value := strings.TrimSpace(g.filterInput.Value())
+		if value != "" && (g.filtering == filterSince || g.filtering == filterUntil) {
+			if err := git.ValidateDate(value); err != nil {
+				g.filterErr = err
+				return nil
+			}
+		}
+
+		switch g.filtering {
+		case filterSearch:
+			// Searching filters what is loaded, there is nothing to reload
+			g.filtering = filterNone
+			g.filterInput.Blur()
+			g.applySearch(value)
+			return nil
+		case filterAuthor:
+			g.author = value
+		case filterSince:
+			g.pendingFrom = value
+			return g.openFilter(filterUntil, g.until)
+		case filterUntil:
+			g.since = g.pendingFrom
+			g.until = value
+		}
+
+		g.filtering = filterNone
+		g.filterInput.Blur()
+		g.cursor = 0
+		g.offset = 0
+		return g.loadCommits()
+
+	case "esc":
+		g.filtering = filterNone
+		g.filterErr = nil
+		g.filterInput.Blur()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	g.filterInput, cmd = g.filterInput.Update(msg)
+	return cmd
+}
+
 func (g *GraphView) View() string {
-	if len(g.commits) == 0 {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Render("Loading commits...")
+	if g.detail != nil {
+		return g.detail.View()
+	}
+
+	if !g.loaded {
+		return g.loader.view("Loading commits...")
 	}
 
 	var b strings.Builder
+	b.WriteString(g.renderHeader())
 
-	// Determine how many commits we can show
-	visibleCount := g.height - 4 // Leave room for header and footer
-	if visibleCount < 1 {
-		visibleCount = 1
+	if len(g.commits) == 0 {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(g.theme.Muted).
+			Render("No commits match the current filters (esc to clear)"))
+		return b.String()
+	}
+
+	if g.rowCount() == 0 {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(g.theme.Muted).
+			Render(fmt.Sprintf("No commits match /%s (esc to clear)", g.search)))
+		return b.String()
 	}
 
+	// Determine how many commits we can show
+	visibleCount := g.visibleRows()
+
 	start := g.offset
 	end := start + visibleCount
-	if end > len(g.commits) {
-		end = len(g.commits)
+	if end > g.rowCount() {
+		end = g.rowCount()
 	}
 
-	for i := start; i < end; i++ {
+	for row := start; row < end; row++ {
+		i := g.commitIndex(row)
 		commit := g.commits[i]
-		graph := ""
-		if i < len(g.graphLines) {
-			// Extract just the graph part from the line
-			graphLine := g.graphLines[i]
-			// Find where the hash starts (after the graph)
-			hashIdx := strings.Index(graphLine, commit.ShortHash)
-			if hashIdx > 0 {
-				graph = graphLine[:hashIdx]
-			} else {
-				graph = "  "
-			}
+		graph := "  "
+		if i < len(g.graphLines) && g.graphLines[i] != "" {
+			graph = g.graphLines[i]
 		}
 
-		line := g.formatCommitLine(commit, graph, i == g.cursor)
+		line := g.formatCommitLine(commit, graph, row == g.cursor)
 		b.WriteString(line + "\n")
 	}
 
+	if g.loadingMore {
+		b.WriteString("  " + g.loader.view("Loading more commits...") + "\n")
+	}
+
+	// Show the committer of the selected commit when it differs from the author
+	if selected := g.SelectedCommit(); selected != nil && selected.CommitterDiffers() {
+		footerStyle := lipgloss.NewStyle().Foreground(g.theme.Secondary)
+		footer := fmt.Sprintf("  authored by %s <%s> • committed by %s <%s> %s",
+			selected.Author, selected.Email,
+			selected.Committer, selected.CommitterEmail,
+			formatRelativeTime(selected.CommitDate))
+		b.WriteString(footerStyle.Render(footer) + "\n")
+	}
+
 	return b.String()
 }
 
+// renderFilterHeader shows the open filter prompt or the active filters
+func (g *GraphView) renderFilterHeader() string {
+	errorStyle := lipgloss.NewStyle().Foreground(g.theme.StatusError)
+
+	if g.filtering != filterNone {
+		header := g.filterInput.View()
+		if g.filterErr != nil {
+			header += "  " + errorStyle.Render(g.filterErr.Error())
+		}
+		return header
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(g.theme.Accent)
+	valueStyle := lipgloss.NewStyle().Foreground(g.theme.Warning)
+	helpStyle := lipgloss.NewStyle().Foreground(g.theme.Muted)
+
+	var parts []string
+	if g.path == "" {
+		help := " (A: all/current"
+		if g.scope.allRefs {
+			help += ", R: remotes"
+		}
+		parts = append(parts, labelStyle.Render("Showing: ")+valueStyle.Render(g.scope.describe())+helpStyle.Render(help+")"))
+	}
+	if g.author != "" {
+		parts = append(parts, labelStyle.Render("Author: ")+valueStyle.Render(g.author))
+	}
+	if g.since != "" {
+		parts = append(parts, labelStyle.Render("Since: ")+valueStyle.Render(g.since))
+	}
+	if g.until != "" {
+		parts = append(parts, labelStyle.Render("Until: ")+valueStyle.Render(g.until))
+	}
+
+	var header string
+	if g.filtered() {
+		help := " (a: author, D: dates, esc: clear)"
+		if g.search != "" {
+			help = " (a: author, D: dates, esc twice: clear)"
+		}
+		header = strings.Join(parts, " • ") + helpStyle.Render(help)
+	} else if len(parts) > 0 {
+		header = strings.Join(parts, " • ")
+	}
+
+	if g.search != "" {
+		search := labelStyle.Render("Search: ") + valueStyle.Render(g.search) +
+			helpStyle.Render(fmt.Sprintf(" (%d of %d commits • n/N: next/previous • esc: clear)", len(g.matches), len(g.commits)))
+		if header != "" {
+			header += "\n"
+		}
+		header += search
+	}
+
+	return header
+}
+
 func (g *GraphView) formatCommitLine(commit models.Commit, graph string, selected bool) string {
 	// Styles
-	hashStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("yellow"))
-	authorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("cyan"))
-	dateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
-	messageStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("white"))
+	hashStyle := lipgloss.NewStyle().Foreground(g.theme.Highlight)
+	authorStyle := lipgloss.NewStyle().Foreground(g.theme.authorColor(commit.Email))
+	dateStyle := lipgloss.NewStyle().Foreground(g.theme.Secondary)
+	messageStyle := lipgloss.NewStyle().Foreground(g.theme.Text)
+	emptyMessageStyle := lipgloss.NewStyle().Foreground(g.theme.Muted).Italic(true)
 	refStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("green")).
+		Foreground(g.theme.Branch).
 		Bold(true)
 	selectedStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("238")).
-		Foreground(lipgloss.Color("white"))
+		Background(g.theme.Selection).
+		Foreground(g.theme.Text)
 
 	// Format relative time
 	relTime := formatRelativeTime(commit.Date)
 
+	hash := commit.ShortHash
+	if g.fullHashes {
+		hash = commit.Hash
+	}
+
 	// Build the line
 	parts := []string{
 		graph,
-		hashStyle.Render(commit.ShortHash),
+		highlightMatches(hash, g.search, hashStyle),
 	}
 
 	// Add refs if any
@@ -164,19 +626,30 @@ func (g *GraphView) formatCommitLine(commit models.Commit, graph string, selecte
 		parts = append(parts, refStyle.Render(refs))
 	}
 
+	// Commits can have an empty subject, don't leave a confusing gap
+	message := highlightMatches(commit.Message, g.search, messageStyle)
+	if strings.TrimSpace(commit.Message) == "" {
+		message = emptyMessageStyle.Render("(no message)")
+	}
+
+	author := authorStyle.Render("<") + highlightMatches(commit.Author, g.search, authorStyle) + authorStyle.Render(">")
+	if g.initials {
+		author = authorStyle.Bold(true).Render(initials(commit.Author))
+	}
+
 	parts = append(parts,
-		messageStyle.Render(commit.Message),
+		message,
 		dateStyle.Render(fmt.Sprintf("- %s", relTime)),
-		authorStyle.Render(fmt.Sprintf("<%s>", commit.Author)),
+		author,
 	)
 
 	line := strings.Join(parts, " ")
 
-	// Truncate if too long
+	// Truncate by visible width, keeping the styles' escape codes intact.
+	// The selection marker takes the other two columns.
 	maxWidth := g.width - 2
-	if maxWidth > 0 && len(line) > maxWidth {
-		// This is approximate since we're not counting ANSI codes properly
-		line = line[:maxWidth-3] + "..."
+	if maxWidth > 0 && lipgloss.Width(line) > maxWidth {
+		line = ansi.Truncate(line, maxWidth, "…")
 	}
 
 	if selected {
@@ -188,37 +661,220 @@ func (g *GraphView) formatCommitLine(commit models.Commit, graph string, selecte
 	return line
 }
 
-func formatRelativeTime(t time.Time) string {
-	now := time.Now()
-	diff := now.Sub(t)
+// initials returns the first letters of the first and last word of name,
+// or its first two letters when it is a single word, e.g. "JD" for
+// "Jane Q. Doe"
+func initials(name string) string {
+	words := strings.Fields(name)
+	switch len(words) {
+	case 0:
+		return "?"
+	case 1:
+		runes := []rune(words[0])
+		if len(runes) > 2 {
+			runes = runes[:2]
+		}
+		return strings.ToUpper(string(runes))
+	}
+	first, _ := utf8.DecodeRuneInString(words[0])
+	last, _ := utf8.DecodeRuneInString(words[len(words)-1])
+	return strings.ToUpper(string([]rune{first, last}))
+}
+
+// reloadScope reloads the graph after its scope changed, keeping the
+// selected commit selected if it is still listed
+func (g *GraphView) reloadScope() tea.Cmd {
+	if selected := g.SelectedCommit(); selected != nil && g.search == "" {
+		g.Select(selected.Hash)
+	}
+	g.cursor = 0
+	g.offset = 0
+	return g.loadCommits()
+}
 
-	switch {
-	case diff < time.Minute:
-		return "just now"
-	case diff < time.Hour:
-		mins := int(diff.Minutes())
-		return fmt.Sprintf("%d min ago", mins)
-	case diff < 24*time.Hour:
-		hours := int(diff.Hours())
-		return fmt.Sprintf("%d hours ago", hours)
-	case diff < 7*24*time.Hour:
-		days := int(diff.Hours() / 24)
-		return fmt.Sprintf("%d days ago", days)
-	case diff < 30*24*time.Hour:
-		weeks := int(diff.Hours() / 24 / 7)
-		return fmt.Sprintf("%d weeks ago", weeks)
-	case diff < 365*24*time.Hour:
-		months := int(diff.Hours() / 24 / 30)
-		return fmt.Sprintf("%d months ago", months)
-	default:
-		years := int(diff.Hours() / 24 / 365)
-		return fmt.Sprintf("%d years ago", years)
+// filtered reports whether git itself narrows the commits, by author or date
+func (g *GraphView) filtered() bool {
+	return g.author != "" || g.since != "" || g.until != ""
+}
+
+// Select selects the commit with hash once the graph loads, if it is on
+// the first page
+func (g *GraphView) Select(hash string) {
+	g.restore = hash
+}
+
+func (g *GraphView) restoreSelection() {
+	if g.restore == "" {
+		return
+	}
+	for i, commit := range g.commits {
+		if commit.Hash == g.restore {
+			g.cursor = i
+			g.scrollToCursor()
+			break
+		}
 	}
+	g.restore = ""
 }
 
 func (g *GraphView) SelectedCommit() *models.Commit {
-	if g.cursor >= 0 && g.cursor < len(g.commits) {
-		return &g.commits[g.cursor]
+	if g.cursor >= 0 && g.cursor < g.rowCount() {
+		return &g.commits[g.commitIndex(g.cursor)]
 	}
 	return nil
 }
+
+// rowCount is the number of commits listed, only the matches while searching
+func (g *GraphView) rowCount() int {
+	if g.search != "" {
+		return len(g.matches)
+	}
+	return len(g.commits)
+}
+
+// commitIndex maps a listed row to its index in commits and graphLines
+func (g *GraphView) commitIndex(row int) int {
+	if g.search != "" {
+		return g.matches[row]
+	}
+	return row
+}
+
+// applySearch lists only the commits matching query, keeping the cursor on
+// the selected commit if it matches. An empty query clears the search.
+func (g *GraphView) applySearch(query string) {
+	if query == "" {
+		g.clearSearch()
+		return
+	}
+
+	selected := -1
+	if g.cursor >= 0 && g.cursor < g.rowCount() {
+		selected = g.commitIndex(g.cursor)
+	}
+
+	g.search = query
+	g.matches = g.findMatches(query)
+	g.cursor = 0
+	for row, i := range g.matches {
+		if i == selected {
+			g.cursor = row
+			break
+		}
+	}
+	g.offset = 0
+	g.scrollToCursor()
+}
+
+// clearSearch lists every commit again, keeping the selected one selected
+func (g *GraphView) clearSearch() {
+	if g.cursor >= 0 && g.cursor < g.rowCount() {
+		g.cursor = g.commitIndex(g.cursor)
+	} else {
+		g.cursor = 0
+	}
+	g.search = ""
+	g.matches = nil
+	g.scrollToCursor()
+}
+
+// findMatches returns the indices of commits whose message, author or hash
+// contains query, ignoring case
+func (g *GraphView) findMatches(query string) []int {
+	if query == "" {
+		return nil
+	}
+
+	query = strings.ToLower(query)
+	var matches []int
+	for i, commit := range g.commits {
+		if strings.Contains(strings.ToLower(commit.Message), query) ||
+			strings.Contains(strings.ToLower(commit.Author), query) ||
+			strings.Contains(strings.ToLower(commit.Hash), query) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// renderHeader renders the lines above the commit list: the picking and
+// file history banners, the filters or filter prompt, and any error
+func (g *GraphView) renderHeader() string {
+	var b strings.Builder
+
+	if g.picking {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(g.theme.Accent).
+			Render("Pick a commit to compare the staged file against (enter: pick, esc: cancel)") + "\n")
+	}
+
+	if g.path != "" {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(g.theme.Accent).
+			Render("History of "+g.path) + lipgloss.NewStyle().
+			Foreground(g.theme.Muted).
+			Render(" (enter: details, esc: back)") + "\n")
+	}
+
+	if header := g.renderFilterHeader(); header != "" {
+		b.WriteString(header + "\n")
+	}
+
+	if g.err != nil {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(g.theme.StatusError).
+			Render(fmt.Sprintf("Error: %v", g.err)) + "\n")
+	}
+
+	return b.String()
+}
+
+// visibleRows is how many commits fit between the header and the footer
+func (g *GraphView) visibleRows() int {
+	visible := g.height - 4 - strings.Count(g.renderHeader(), "\n")
+	if visible < 1 {
+		visible = 1
+	}
+	return visible
+}
+
+// scrollToCursor adjusts the offset so the cursor row is on screen
+func (g *GraphView) scrollToCursor() {
+	visible := g.visibleRows()
+	if g.cursor < g.offset {
+		g.offset = g.cursor
+	}
+	if g.cursor >= g.offset+visible {
+		g.offset = g.cursor - visible + 1
+	}
+}
+
+// highlightMatches renders text in style, picking out every occurrence of
+// query, ignoring case, in reverse video
+func highlightMatches(text, query string, style lipgloss.Style) string {
+	lower := strings.ToLower(text)
+	query = strings.ToLower(query)
+	// Lowercasing can change the length of some non-ASCII text, offsets
+	// would no longer line up
+	if query == "" || len(lower) != len(text) {
+		return style.Render(text)
+	}
+
+	matchStyle := style.Reverse(true)
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			b.WriteString(style.Render(text[:i]))
+		}
+		b.WriteString(matchStyle.Render(text[i : i+len(query)]))
+		text, lower = text[i+len(query):], lower[i+len(query):]
+	}
+	if text != "" {
+		b.WriteString(style.Render(text))
+	}
+	return b.String()
+}
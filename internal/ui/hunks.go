@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+type diffLoadedMsg struct {
+	diff     string
+	unstaged string // set for partially staged files, diff is then the staged side
+	staged   bool   // diff is of the index rather than the working tree
+}
+
+// loadFileDiff fetches the diff of a file in the index or working tree,
+// whichever it has changes in. A partially staged file gets both.
+func loadFileDiff(file models.FileChange) tea.Msg {
+	diff, err := git.GetDiff(file.Path, file.IsStaged)
+	if err != nil {
+		return errMsg{err}
+	}
+	msg := diffLoadedMsg{diff: diff, staged: file.IsStaged}
+	if file.IsPartiallyStaged() {
+		// Show what a commit would take and what it would leave behind
+		msg.unstaged, err = git.GetDiff(file.Path, false)
+		if err != nil {
+			return errMsg{err}
+		}
+	}
+	return msg
+}
+
+// hunkList is a file's diff split into hunks that are staged or unstaged
+// one at a time, as used by the staging view and the commit flow
+type hunkList struct {
+	items     []models.Hunk
+	staged    int // items before this index are in the index and unstage on space
+	cursor    int
+	sectioned bool // partially staged, with staged and unstaged hunks
+}
+
+// load replaces the hunks with those of a freshly loaded diff, keeping the
+// cursor in bounds
+func (h *hunkList) load(msg diffLoadedMsg) {
+	h.items = git.ParseHunks(msg.diff)
+	h.staged = 0
+	if msg.staged {
+		h.staged = len(h.items)
+	}
+	h.items = append(h.items, git.ParseHunks(msg.unstaged)...)
+	h.sectioned = msg.unstaged != ""
+	h.clamp()
+}
+
+// clear drops the hunks, e.g. for a diff that can't be applied to the index
+func (h *hunkList) clear() {
+	h.items = nil
+	h.staged = 0
+	h.sectioned = false
+	h.clamp()
+}
+
+func (h *hunkList) clamp() {
+	if h.cursor >= len(h.items) {
+		h.cursor = len(h.items) - 1
+	}
+	if h.cursor < 0 {
+		h.cursor = 0
+	}
+}
+
+func (h *hunkList) down() {
+	if h.cursor < len(h.items)-1 {
+		h.cursor++
+	}
+}
+
+func (h *hunkList) up() {
+	if h.cursor > 0 {
+		h.cursor--
+	}
+}
+
+// action names what space does to the selected hunk
+func (h *hunkList) action() string {
+	if h.cursor < h.staged {
+		return "unstage"
+	}
+	return "stage"
+}
+
+// toggle stages or unstages the selected hunk of path, then returns the
+// message reload produces so the caller can refresh its file list
+func (h *hunkList) toggle(path string, reload func() tea.Msg) tea.Cmd {
+	if h.cursor >= len(h.items) {
+		return nil
+	}
+
+	hunk := h.items[h.cursor]
+	staged := h.cursor < h.staged
+	return func() tea.Msg {
+		var err error
+		if staged {
+			err = git.UnstageHunk(path, hunk)
+		} else {
+			err = git.StageHunk(path, hunk)
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+		return reload()
+	}
+}
+
+// render renders the hunks starting at the selected one, marking it in the
+// gutter, below a help line ending in back
+func (h *hunkList) render(t *Theme, width, maxLines int, splitDiff, wordDiff bool, back string) string {
+	markerStyle := lipgloss.NewStyle().Foreground(t.Accent)
+	helpStyle := lipgloss.NewStyle().Foreground(t.Muted)
+
+	help := helpStyle.Render(fmt.Sprintf("Hunk %d/%d • space: %s • j/k: select hunk • %s",
+		h.cursor+1, len(h.items), h.action(), back))
+
+	visible := h.items[h.cursor:]
+
+	// Side-by-side needs room for two columns, fall back to unified otherwise
+	if splitDiff && width >= minSplitDiffWidth {
+		var text []string
+		for _, hunk := range visible {
+			text = append(text, hunk.Header)
+			text = append(text, hunk.Lines...)
+		}
+		return help + "\n" + renderSplitDiff(t, strings.Join(text, "\n"), width, maxLines-1)
+	}
+
+	var lines []string
+	for i, hunk := range visible {
+		if h.sectioned {
+			// Head each side of a partially staged file where it starts
+			switch index := h.cursor + i; {
+			case i == 0 && index < h.staged:
+				lines = append(lines, diffSectionHeader(t, "Staged"))
+			case index == h.staged:
+				lines = append(lines, diffSectionHeader(t, "Unstaged"))
+			}
+		}
+		if len(lines) >= maxLines {
+			break
+		}
+		gutter := "  "
+		if i == 0 {
+			gutter = markerStyle.Render("▌ ")
+		}
+		text := strings.Join(append([]string{hunk.Header}, hunk.Lines...), "\n")
+		for _, line := range strings.Split(colorizeDiff(t, text, wordDiff), "\n") {
+			lines = append(lines, gutter+line)
+		}
+	}
+	if len(lines) > maxLines-1 {
+		lines = lines[:maxLines-1]
+		lines = append(lines, "... (truncated)")
+	}
+
+	return help + "\n" + strings.Join(lines, "\n")
+}
+
+// diffSectionHeader renders the title above the staged or unstaged side of
+// a partially staged file's diff
+func diffSectionHeader(t *Theme, title string) string {
+	return lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render(title)
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newSpinner returns the spinner every view shows while git works
+func newSpinner(theme *Theme) spinner.Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(theme.Brand)
+	return s
+}
+
+// loader tracks whether a view's loader tea.Cmd is in flight, spinning
+// while it is so slow git commands don't look like a frozen UI
+type loader struct {
+	spinner   spinner.Model
+	textStyle lipgloss.Style
+	loading   bool
+}
+
+func newLoader(theme *Theme) loader {
+	return loader{
+		spinner:   newSpinner(theme),
+		textStyle: lipgloss.NewStyle().Foreground(theme.Muted),
+	}
+}
+
+// start marks cmd as in flight and starts the spinner, until stop is
+// called from its result message
+func (l *loader) start(cmd tea.Cmd) tea.Cmd {
+	l.loading = true
+	// The spinner drops ticks of a loop it has moved past, so restarting
+	// while one is still running doesn't speed it up
+	return tea.Batch(cmd, l.spinner.Tick)
+}
+
+func (l *loader) stop() {
+	l.loading = false
+}
+
+// tick advances the spinner, letting its tick loop end once loading stops.
+// Ticks of other spinners are ignored.
+func (l *loader) tick(msg spinner.TickMsg) tea.Cmd {
+	if !l.loading {
+		return nil
+	}
+	var cmd tea.Cmd
+	l.spinner, cmd = l.spinner.Update(msg)
+	return cmd
+}
+
+// view renders the spinner followed by text, e.g. "Loading stashes..."
+func (l loader) view(text string) string {
+	return l.spinner.View() + " " + l.textStyle.Render(text)
+}
+
+// indicator renders the spinner after a header while a reload runs, and
+// nothing otherwise
+func (l loader) indicator() string {
+	if !l.loading {
+		return ""
+	}
+	return " " + l.spinner.View()
+}
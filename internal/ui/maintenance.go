@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+type maintenanceTask struct {
+	name        string
+	description string
+	run         func(ctx context.Context) error
+}
+
+var maintenanceTasks = []maintenanceTask{
+	{"git gc", "Pack loose objects and prune unreachable ones", git.RunGCContext},
+	{"git maintenance run", "Run the repository's configured maintenance tasks", git.RunMaintenanceContext},
+}
+
+type maintenanceDoneMsg struct {
+	before int64
+	after  int64
+	err    error
+}
+
+type maintenanceCloseMsg struct{}
+
+type repoStatsMsg struct {
+	stats models.RepoStats
+	err   error
+}
+
+type MaintenanceView struct {
+	theme    *Theme
+	cursor   int
+	keys     config.KeyMap
+	running  bool
+	cancel   context.CancelFunc
+	spinner  spinner.Model
+	result   string
+	err      error
+	stats    *models.RepoStats
+	statsErr error
+	width    int
+	height   int
+}
+
+func NewMaintenanceView(cfg *config.Config, theme *Theme) *MaintenanceView {
+	return &MaintenanceView{
+		theme:   theme,
+		cursor:  0,
+		keys:    cfg.Keys,
+		spinner: newSpinner(theme),
+	}
+}
+
+func (m *MaintenanceView) Init() tea.Cmd {
+	return loadRepoStats
+}
+
+func loadRepoStats() tea.Msg {
+	stats, err := git.GetRepoStats()
+	return repoStatsMsg{stats: stats, err: err}
+}
+
+func (m *MaintenanceView) Update(msg tea.Msg) (*MaintenanceView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case maintenanceDoneMsg:
+		m.running = false
+		m.cancel = nil
+		if msg.err != nil {
+			m.err = msg.err
+			return m, loadRepoStats
+		}
+		m.result = fmt.Sprintf("Done: %s → %s (reclaimed %s)",
+			formatBytes(msg.before), formatBytes(msg.after), formatBytes(msg.before-msg.after))
+		return m, loadRepoStats
+
+	case repoStatsMsg:
+		if msg.err != nil {
+			m.statsErr = msg.err
+			return m, nil
+		}
+		m.stats = &msg.stats
+		m.statsErr = nil
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.running {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			// Only allow cancelling while a task runs
+			if msg.String() == "esc" && m.cancel != nil {
+				m.cancel()
+			}
+			return m, nil
+		}
+
+		switch key := msg.String(); {
+		case key == "esc":
+			return m, func() tea.Msg { return maintenanceCloseMsg{} }
+
+		case m.keys.Down.Matches(key):
+			if m.cursor < len(maintenanceTasks)-1 {
+				m.cursor++
+			}
+
+		case m.keys.Up.Matches(key):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case key == "enter":
+			return m, m.runTask(maintenanceTasks[m.cursor])
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+
+	return m, nil
+}
+
+// runTask runs a maintenance task in the background, measuring .git before and after
+func (m *MaintenanceView) runTask(task maintenanceTask) tea.Cmd {
+	ctx, cancel := context.WithTimeout(context.Background(), git.MaintenanceTimeout)
+	m.running = true
+	m.cancel = cancel
+	m.result = ""
+	m.err = nil
+
+	run := func() tea.Msg {
+		defer cancel()
+
+		before, err := git.GetGitDirSize()
+		if err != nil {
+			return maintenanceDoneMsg{err: err}
+		}
+		if err := task.run(ctx); err != nil {
+			return maintenanceDoneMsg{err: err}
+		}
+		after, err := git.GetGitDirSize()
+		if err != nil {
+			return maintenanceDoneMsg{err: err}
+		}
+		return maintenanceDoneMsg{before: before, after: after}
+	}
+
+	return tea.Batch(run, m.spinner.Tick)
+}
+
+func (m *MaintenanceView) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Accent).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().Background(m.theme.Panel)
+	descStyle := lipgloss.NewStyle().Foreground(m.theme.Muted)
+	successStyle := lipgloss.NewStyle().Foreground(m.theme.StatusOK)
+	errorStyle := lipgloss.NewStyle().Foreground(m.theme.StatusError)
+	helpStyle := lipgloss.NewStyle().Foreground(m.theme.Muted)
+
+	var b strings.Builder
+	b.WriteString("\n" + titleStyle.Render(" Maintenance ") + "\n\n")
+	b.WriteString(m.renderStats() + "\n")
+
+	for i, task := range maintenanceTasks {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		line := fmt.Sprintf("%s%-20s %s", cursor, task.name, descStyle.Render(task.description))
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.running:
+		b.WriteString(fmt.Sprintf("  %s Running %s...\n\n", m.spinner.View(), maintenanceTasks[m.cursor].name))
+		b.WriteString(helpStyle.Render("  esc: cancel"))
+		return b.String()
+	case m.err != nil:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("  Error: %v", m.err)) + "\n\n")
+	case m.result != "":
+		b.WriteString(successStyle.Render("  "+m.result) + "\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("  enter: run • j/k: select • esc: back"))
+	return b.String()
+}
+
+// renderStats shows the object database footprint from git count-objects
+func (m *MaintenanceView) renderStats() string {
+	labelStyle := lipgloss.NewStyle().Foreground(m.theme.Accent)
+	valueStyle := lipgloss.NewStyle().Foreground(m.theme.Text)
+	mutedStyle := lipgloss.NewStyle().Foreground(m.theme.Muted)
+	warnStyle := lipgloss.NewStyle().Foreground(m.theme.Warning)
+
+	if m.statsErr != nil {
+		return mutedStyle.Render(fmt.Sprintf("  Could not read repository stats: %v", m.statsErr)) + "\n"
+	}
+	if m.stats == nil {
+		return mutedStyle.Render("  Reading repository stats...") + "\n"
+	}
+
+	s := m.stats
+	row := func(label, value string) string {
+		return "  " + labelStyle.Render(fmt.Sprintf("%-16s", label)) + valueStyle.Render(value) + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(row("Repository size", formatBytes(s.TotalSize())))
+	b.WriteString(row("Objects", fmt.Sprintf("%d (%d loose, %d packed)", s.TotalObjects(), s.LooseObjects, s.PackedObjects)))
+	b.WriteString(row("Loose size", formatBytes(s.LooseSize)))
+	b.WriteString(row("Packs", fmt.Sprintf("%d (%s)", s.Packs, formatBytes(s.PackSize))))
+	if s.PrunePackable > 0 {
+		b.WriteString("  " + warnStyle.Render(fmt.Sprintf("%d loose objects are already packed, gc will prune them", s.PrunePackable)) + "\n")
+	}
+	if s.Garbage > 0 {
+		b.WriteString("  " + warnStyle.Render(fmt.Sprintf("%d garbage files (%s)", s.Garbage, formatBytes(s.GarbageSize))) + "\n")
+	}
+	return b.String()
+}
+
+// formatBytes renders a byte count in human-readable units
+func formatBytes(n int64) string {
+	if n < 0 {
+		return "-" + formatBytes(-n)
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
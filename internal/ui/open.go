@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+)
+
+// openURL opens a URL in the user's default browser
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// foregroundFunc runs a function the way tea.Exec runs a process, with the
+// terminal handed over while it runs, so that anything it starts can prompt
+// there, e.g. a pinentry asking for a signing key's passphrase
+type foregroundFunc func() error
+
+func (f foregroundFunc) Run() error          { return f() }
+func (f foregroundFunc) SetStdin(io.Reader)  {}
+func (f foregroundFunc) SetStdout(io.Writer) {}
+func (f foregroundFunc) SetStderr(io.Writer) {}
+
+// editorCommand builds a command opening path in $VISUAL, $EDITOR or git's
+// core.editor, which may include arguments such as "code --wait", falling
+// back to vi
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = git.GetCoreEditor()
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], path)...)
+}
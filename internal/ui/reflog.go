@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+// reflogLimit is how many reflog entries the view loads
+const reflogLimit = 500
+
+type reflogLoadedMsg struct {
+	entries []models.ReflogEntry
+}
+
+// reflogBranchCreatedMsg reports the result of creating a branch at a
+// reflog entry
+type reflogBranchCreatedMsg struct {
+	name string
+	hash string
+	err  error
+}
+
+type reflogCloseMsg struct{}
+
+// ReflogView lists where HEAD has been, to recover commits lost to a reset
+// or a deleted branch
+type ReflogView struct {
+	theme      *Theme
+	entries    []models.ReflogEntry
+	loaded     bool
+	loader     loader
+	cursor     int
+	fullHashes bool
+	keys       config.KeyMap
+	status     string
+	err        error
+	width      int
+	height     int
+}
+
+func NewReflogView(cfg *config.Config, theme *Theme) *ReflogView {
+	return &ReflogView{
+		theme:      theme,
+		loader:     newLoader(theme),
+		cursor:     0,
+		fullHashes: cfg.FullHashes,
+		keys:       cfg.Keys,
+	}
+}
+
+func (r *ReflogView) Init() tea.Cmd {
+	return r.loadReflog()
+}
+
+func (r *ReflogView) loadReflog() tea.Cmd {
+	return r.loader.start(func() tea.Msg {
+		entries, err := git.Reflog(reflogLimit)
+		if err != nil {
+			return errMsg{err}
+		}
+		return reflogLoadedMsg{entries}
+	})
+}
+
+func (r *ReflogView) Update(msg tea.Msg) (*ReflogView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case reflogLoadedMsg:
+		r.entries = msg.entries
+		r.loaded = true
+		r.loader.stop()
+		if r.cursor >= len(r.entries) {
+			r.cursor = len(r.entries) - 1
+		}
+		if r.cursor < 0 {
+			r.cursor = 0
+		}
+
+	case reflogBranchCreatedMsg:
+		if msg.err != nil {
+			r.err = msg.err
+			r.status = ""
+			return r, nil
+		}
+		r.err = nil
+		r.status = fmt.Sprintf("Created branch %s at %s", msg.name, msg.hash)
+
+	case errMsg:
+		r.err = msg.err
+		r.loaded = true
+		r.loader.stop()
+
+	case spinner.TickMsg:
+		return r, r.loader.tick(msg)
+
+	case tea.KeyMsg:
+		switch key := msg.String(); {
+		case key == "esc":
+			return r, func() tea.Msg { return reflogCloseMsg{} }
+
+		case r.keys.Down.Matches(key):
+			if r.cursor < len(r.entries)-1 {
+				r.cursor++
+			}
+
+		case r.keys.Up.Matches(key):
+			if r.cursor > 0 {
+				r.cursor--
+			}
+
+		case r.keys.Top.Matches(key):
+			r.cursor = 0
+
+		case r.keys.Bottom.Matches(key):
+			r.cursor = len(r.entries) - 1
+
+		case key == "#":
+			// Toggle full/short hashes
+			r.fullHashes = !r.fullHashes
+
+		case key == "b":
+			// Recover the selected entry as a new branch
+			if entry := r.SelectedEntry(); entry != nil {
+				hash, short := entry.Hash, entry.ShortHash
+				prompt := fmt.Sprintf("Create a branch at %s (%s)?", short, entry.Selector)
+				return r, requestConfirm(prompt, func() tea.Msg {
+					name := recoveredBranchName()
+					return reflogBranchCreatedMsg{name: name, hash: short, err: git.CreateBranchAt(name, hash)}
+				}, nil)
+			}
+
+		case key == "r":
+			return r, r.loadReflog()
+		}
+
+	case tea.WindowSizeMsg:
+		r.width = msg.Width
+		r.height = msg.Height
+	}
+
+	return r, nil
+}
+
+// recoveredBranchName picks "recovered", or "recovered-2" and so on when
+// earlier recoveries still exist
+func recoveredBranchName() string {
+	name := "recovered"
+	for i := 2; git.BranchExists(name); i++ {
+		name = fmt.Sprintf("recovered-%d", i)
+	}
+	return name
+}
+
+func (r *ReflogView) View() string {
+	grayStyle := lipgloss.NewStyle().Foreground(r.theme.Muted)
+
+	if !r.loaded {
+		return r.loader.view("Loading reflog...")
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(r.theme.Accent).
+		Bold(true).
+		MarginBottom(1)
+
+	hashStyle := lipgloss.NewStyle().Foreground(r.theme.Highlight)
+	selectorStyle := lipgloss.NewStyle().Foreground(r.theme.Secondary)
+	actionStyle := lipgloss.NewStyle().Foreground(r.theme.Branch)
+	messageStyle := lipgloss.NewStyle().Foreground(r.theme.Text)
+	dateStyle := lipgloss.NewStyle().Foreground(r.theme.Secondary)
+	selectedStyle := lipgloss.NewStyle().Background(r.theme.Selection)
+	statusStyle := lipgloss.NewStyle().Foreground(r.theme.StatusOK)
+	errorStyle := lipgloss.NewStyle().Foreground(r.theme.StatusError)
+
+	var out strings.Builder
+
+	out.WriteString(headerStyle.Render(fmt.Sprintf("Reflog (%d entries)", len(r.entries))+r.loader.indicator()) + "\n")
+
+	if len(r.entries) == 0 {
+		out.WriteString(grayStyle.Render("  No reflog entries") + "\n")
+	}
+
+	// Keep the cursor in view, the reflog is usually longer than the screen
+	visibleHeight := r.height - 6
+	if visibleHeight < 5 {
+		visibleHeight = 5
+	}
+	start := r.cursor - visibleHeight/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + visibleHeight
+	if end > len(r.entries) {
+		end = len(r.entries)
+		start = end - visibleHeight
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	for i := start; i < end; i++ {
+		entry := r.entries[i]
+
+		hash := entry.ShortHash
+		if r.fullHashes {
+			hash = entry.Hash
+		}
+
+		line := hashStyle.Render(hash)
+		line += " " + selectorStyle.Render(entry.Selector)
+		line += " " + actionStyle.Render(entry.Action)
+		if entry.Message != "" {
+			line += " " + messageStyle.Render(entry.Message)
+		}
+		line += " " + dateStyle.Render(formatRelativeTime(entry.Date))
+
+		if i == r.cursor {
+			line = selectedStyle.Render("▸ " + line)
+		} else {
+			line = "  " + line
+		}
+
+		out.WriteString(line + "\n")
+	}
+
+	if r.err != nil {
+		out.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %v", r.err)) + "\n")
+	} else if r.status != "" {
+		out.WriteString("\n" + statusStyle.Render(r.status) + "\n")
+	}
+
+	out.WriteString("\n" + grayStyle.Render("b: create a branch here • #: full hashes • r: refresh • esc: back"))
+
+	return out.String()
+}
+
+func (r *ReflogView) SelectedEntry() *models.ReflogEntry {
+	if r.cursor >= 0 && r.cursor < len(r.entries) {
+		return &r.entries[r.cursor]
+	}
+	return nil
+}
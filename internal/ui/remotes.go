@@ -0,0 +1,312 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+type remotesLoadedMsg struct {
+	remotes       []models.Remote
+	defaultRemote string
+}
+
+// remoteChangedMsg reports the result of adding, removing, renaming or
+// choosing a remote
+type remoteChangedMsg struct {
+	status string
+	err    error
+}
+
+type remoteViewCloseMsg struct{}
+
+// remotePrompt is what the remote view's text input is asking for
+type remotePrompt int
+
+const (
+	remotePromptNone   remotePrompt = iota
+	remotePromptName                // name of a new remote
+	remotePromptURL                 // URL of the new remote named in addName
+	remotePromptRename              // new name for the selected remote
+)
+
+// RemoteView lists the configured remotes and adds, removes, renames them
+// or picks the one the default branch comes from
+type RemoteView struct {
+	theme         *Theme
+	remotes       []models.Remote
+	defaultRemote string
+	loaded        bool
+	loader        loader
+	cursor        int
+	keys          config.KeyMap
+	prompt        remotePrompt
+	input         textinput.Model
+	addName       string
+	status        string
+	err           error
+	width         int
+	height        int
+}
+
+func NewRemoteView(cfg *config.Config, theme *Theme) *RemoteView {
+	ti := textinput.New()
+	ti.CharLimit = 500
+	ti.Width = 60
+
+	return &RemoteView{
+		theme:  theme,
+		loader: newLoader(theme),
+		cursor: 0,
+		keys:   cfg.Keys,
+		input:  ti,
+	}
+}
+
+func (r *RemoteView) Init() tea.Cmd {
+	return r.loadRemotes()
+}
+
+func (r *RemoteView) loadRemotes() tea.Cmd {
+	return r.loader.start(func() tea.Msg {
+		remotes, err := git.ListRemotes()
+		if err != nil {
+			return errMsg{err}
+		}
+		return remotesLoadedMsg{remotes: remotes, defaultRemote: git.DefaultRemote()}
+	})
+}
+
+func (r *RemoteView) Update(msg tea.Msg) (*RemoteView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case remotesLoadedMsg:
+		r.remotes = msg.remotes
+		r.defaultRemote = msg.defaultRemote
+		r.loaded = true
+		r.loader.stop()
+		if r.cursor >= len(r.remotes) {
+			r.cursor = len(r.remotes) - 1
+		}
+		if r.cursor < 0 {
+			r.cursor = 0
+		}
+
+	case remoteChangedMsg:
+		if msg.err != nil {
+			r.err = msg.err
+			r.status = ""
+			return r, r.loadRemotes()
+		}
+		r.err = nil
+		r.status = msg.status
+		return r, r.loadRemotes()
+
+	case errMsg:
+		r.err = msg.err
+		r.loaded = true
+		r.loader.stop()
+
+	case spinner.TickMsg:
+		return r, r.loader.tick(msg)
+
+	case tea.KeyMsg:
+		if r.prompt != remotePromptNone {
+			return r, r.updatePrompt(msg)
+		}
+
+		switch key := msg.String(); {
+		case key == "esc":
+			return r, func() tea.Msg { return remoteViewCloseMsg{} }
+
+		case r.keys.Down.Matches(key):
+			if r.cursor < len(r.remotes)-1 {
+				r.cursor++
+			}
+
+		case r.keys.Up.Matches(key):
+			if r.cursor > 0 {
+				r.cursor--
+			}
+
+		case key == "a":
+			// Add a remote, asking for its name and then its URL
+			return r, r.openPrompt(remotePromptName, "")
+
+		case key == "r":
+			// Rename the selected remote
+			if remote := r.SelectedRemote(); remote != nil {
+				return r, r.openPrompt(remotePromptRename, remote.Name)
+			}
+
+		case key == "d":
+			// Remove the selected remote
+			if remote := r.SelectedRemote(); remote != nil {
+				name := remote.Name
+				prompt := fmt.Sprintf("Remove remote %s? Its remote-tracking branches are deleted too.", name)
+				return r, requestConfirm(prompt, func() tea.Msg {
+					return remoteChangedMsg{status: "Removed " + name, err: git.RemoveRemote(name)}
+				}, nil)
+			}
+
+		case key == "enter":
+			// Use the selected remote for the default branch and first pushes
+			if remote := r.SelectedRemote(); remote != nil {
+				name := remote.Name
+				return r, func() tea.Msg {
+					return remoteChangedMsg{status: "Using " + name + " as the default remote", err: git.SetDefaultRemote(name)}
+				}
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		r.width = msg.Width
+		r.height = msg.Height
+	}
+
+	return r, nil
+}
+
+// openPrompt focuses the text input for prompt, starting from value
+func (r *RemoteView) openPrompt(prompt remotePrompt, value string) tea.Cmd {
+	switch prompt {
+	case remotePromptName:
+		r.input.Prompt = "Name: "
+		r.input.Placeholder = "upstream"
+	case remotePromptURL:
+		r.input.Prompt = "URL: "
+		r.input.Placeholder = "git@github.com:user/repo.git"
+	case remotePromptRename:
+		r.input.Prompt = "Rename to: "
+		r.input.Placeholder = ""
+	}
+	r.prompt = prompt
+	r.err = nil
+	r.status = ""
+	r.input.SetValue(value)
+	r.input.CursorEnd()
+	return r.input.Focus()
+}
+
+// updatePrompt handles keys while the text input is open
+func (r *RemoteView) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		r.closePrompt()
+		return nil
+
+	case "enter":
+		value := strings.TrimSpace(r.input.Value())
+		if value == "" {
+			return nil
+		}
+		switch r.prompt {
+		case remotePromptName:
+			r.addName = value
+			return r.openPrompt(remotePromptURL, "")
+		case remotePromptURL:
+			name := r.addName
+			r.closePrompt()
+			return func() tea.Msg {
+				return remoteChangedMsg{status: "Added " + name, err: git.AddRemote(name, value)}
+			}
+		case remotePromptRename:
+			remote := r.SelectedRemote()
+			r.closePrompt()
+			if remote == nil || remote.Name == value {
+				return nil
+			}
+			oldName := remote.Name
+			return func() tea.Msg {
+				return remoteChangedMsg{status: "Renamed " + oldName + " to " + value, err: git.RenameRemote(oldName, value)}
+			}
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	r.input, cmd = r.input.Update(msg)
+	return cmd
+}
+
+func (r *RemoteView) closePrompt() {
+	r.prompt = remotePromptNone
+	r.input.Blur()
+}
+
+func (r *RemoteView) View() string {
+	grayStyle := lipgloss.NewStyle().Foreground(r.theme.Muted)
+
+	if !r.loaded {
+		return r.loader.view("Loading remotes...")
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(r.theme.Accent).
+		Bold(true).
+		MarginBottom(1)
+
+	nameStyle := lipgloss.NewStyle().Foreground(r.theme.Branch).Bold(true)
+	urlStyle := lipgloss.NewStyle().Foreground(r.theme.Text)
+	labelStyle := lipgloss.NewStyle().Foreground(r.theme.Secondary)
+	defaultStyle := lipgloss.NewStyle().Foreground(r.theme.Success)
+	selectedStyle := lipgloss.NewStyle().Background(r.theme.Selection)
+	statusStyle := lipgloss.NewStyle().Foreground(r.theme.StatusOK)
+	errorStyle := lipgloss.NewStyle().Foreground(r.theme.StatusError)
+
+	var out strings.Builder
+
+	out.WriteString(headerStyle.Render(fmt.Sprintf("Remotes (%d)", len(r.remotes))+r.loader.indicator()) + "\n")
+
+	if len(r.remotes) == 0 {
+		out.WriteString(grayStyle.Render("  No remotes, press a to add one") + "\n")
+	}
+
+	for i, remote := range r.remotes {
+		line := nameStyle.Render(remote.Name)
+		if remote.Name == r.defaultRemote {
+			line += " " + defaultStyle.Render("(default)")
+		}
+		line += "  " + urlStyle.Render(remote.FetchURL)
+		if remote.PushURL != "" && remote.PushURL != remote.FetchURL {
+			line += "  " + labelStyle.Render("push:") + " " + urlStyle.Render(remote.PushURL)
+		}
+
+		if i == r.cursor {
+			line = selectedStyle.Render("▸ " + line)
+		} else {
+			line = "  " + line
+		}
+
+		out.WriteString(line + "\n")
+	}
+
+	if r.prompt != remotePromptNone {
+		out.WriteString("\n" + r.input.View() + "\n")
+		out.WriteString(grayStyle.Render("enter: confirm • esc: cancel"))
+		return out.String()
+	}
+
+	if r.err != nil {
+		out.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %v", r.err)) + "\n")
+	} else if r.status != "" {
+		out.WriteString("\n" + statusStyle.Render(r.status) + "\n")
+	}
+
+	out.WriteString("\n" + grayStyle.Render("enter: use as default remote • a: add • r: rename • d: remove • esc: back"))
+
+	return out.String()
+}
+
+func (r *RemoteView) SelectedRemote() *models.Remote {
+	if r.cursor >= 0 && r.cursor < len(r.remotes) {
+		return &r.remotes[r.cursor]
+	}
+	return nil
+}
@@ -2,36 +2,74 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
 	"github.com/Johannes-Berggren/GitGoblin/internal/git"
 	"github.com/Johannes-Berggren/GitGoblin/internal/models"
 )
 
 type StagingView struct {
-	files    []models.FileChange
-	cursor   int
-	width    int
-	height   int
-	showDiff bool
-	diff     string
+	theme       *Theme
+	cfg         *config.Config
+	keys        config.KeyMap
+	files       []models.FileChange // files shown, after exclude patterns and the filter
+	allFiles    []models.FileChange
+	hiddenCount int
+	filter      string // glob or substring the listed paths must match, empty for all
+	filtering   bool   // prompting for the filter
+	filterInput textinput.Model
+	showHidden  bool
+	showIgnored bool            // list ignored files too
+	expanded    map[string]bool // collapsed directories listed file by file
+	cursor      int
+	width       int
+	height      int
+	showDiff    bool
+	splitDiff   bool // side-by-side instead of unified diff
+	wordDiff    bool // highlight changed words within modified lines
+	diff        string
+	unstaged    string // working-tree diff of a partially staged file, diff then holds the staged one
+	hunks       hunkList
+	diffFocus   bool       // j/k and space act on hunks instead of files
+	focusPath   string     // file to select once the list loads
+	picker      *GraphView // commit picker for compareTo, nil when closed
+	history     *GraphView // history of the selected file, nil when closed
+	compareTo   string     // commit the staged file is diffed against, empty for the usual diff
 }
 
-func NewStagingView() *StagingView {
+func NewStagingView(cfg *config.Config, theme *Theme) *StagingView {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "*.go, docs/*.md or part of a path"
+	ti.CharLimit = 100
+	ti.Width = 40
+
 	return &StagingView{
-		cursor:   0,
-		showDiff: false,
+		theme:       theme,
+		cfg:         cfg,
+		keys:        cfg.Keys,
+		cursor:      0,
+		showDiff:    false,
+		wordDiff:    true,
+		expanded:    make(map[string]bool),
+		filterInput: ti,
 	}
 }
 
+type stagingCloseMsg struct{}
+
 type filesLoadedMsg struct {
 	files []models.FileChange
 }
 
-type diffLoadedMsg struct {
-	diff string
+// editorClosedMsg reports that the editor opened from the staging view exited
+type editorClosedMsg struct {
+	err error
 }
 
 func (s *StagingView) Init() tea.Cmd {
@@ -39,8 +77,9 @@ func (s *StagingView) Init() tea.Cmd {
 }
 
 func (s *StagingView) loadFiles() tea.Cmd {
+	opts := s.statusOptions()
 	return func() tea.Msg {
-		files, err := git.GetWorkingTreeStatus()
+		files, err := git.GetWorkingTreeStatusWith(opts)
 		if err != nil {
 			return errMsg{err}
 		}
@@ -54,62 +93,187 @@ func (s *StagingView) loadDiff() tea.Cmd {
 	}
 
 	file := s.files[s.cursor]
+	compareTo := s.compareTo
 	return func() tea.Msg {
-		diff, err := git.GetDiff(file.Path, file.IsStaged)
-		if err != nil {
-			return errMsg{err}
+		if compareTo != "" {
+			diff, err := git.GetDiffAgainstCommit(file.Path, compareTo)
+			if err != nil {
+				return errMsg{err}
+			}
+			return diffLoadedMsg{diff: diff}
 		}
-		return diffLoadedMsg{diff}
+		return loadFileDiff(file)
 	}
 }
 
 func (s *StagingView) Update(msg tea.Msg) (*StagingView, tea.Cmd) {
+	if s.picker != nil {
+		if cmd, handled := s.updatePicker(msg); handled {
+			return s, cmd
+		}
+	}
+	if s.history != nil {
+		if cmd, handled := s.updateHistory(msg); handled {
+			return s, cmd
+		}
+	}
+
 	switch msg := msg.(type) {
 	case filesLoadedMsg:
-		s.files = msg.files
+		// Follow the selected file by path, the list may have shifted
+		selected := s.selectedPath()
+		s.allFiles = msg.files
+		s.applyExcludes()
+		if s.focusPath != "" {
+			s.selectPath(s.focusPath)
+			s.focusPath = ""
+		} else if i := indexOfPath(s.files, selected); i >= 0 {
+			s.cursor = i
+		}
+		if s.selectedPath() != selected {
+			s.hunks.cursor = 0
+		}
 		if len(s.files) > 0 && s.showDiff {
 			return s, s.loadDiff()
 		}
 
+	case editorClosedMsg:
+		if msg.err != nil {
+			return s, func() tea.Msg { return errMsg{msg.err} }
+		}
+		return s, s.loadFiles()
+
 	case diffLoadedMsg:
 		s.diff = msg.diff
+		s.unstaged = msg.unstaged
+		s.hunks.load(msg)
+		if s.compareTo != "" {
+			// Hunks against an old commit can't be applied to the index
+			s.hunks.clear()
+		}
+		if len(s.hunks.items) == 0 {
+			s.diffFocus = false
+		}
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "j", "down":
+		if s.filtering {
+			return s, s.updateFilterInput(msg)
+		}
+		if s.diffFocus {
+			return s, s.updateDiffFocus(msg)
+		}
+
+		switch key := msg.String(); {
+		case key == "esc" && s.compareTo != "":
+			// Back to the usual diff
+			s.compareTo = ""
+			s.hunks.cursor = 0
+			if s.showDiff {
+				return s, s.loadDiff()
+			}
+
+		case key == "esc" && s.filter != "":
+			// Back to the full list
+			return s, s.setFilter("")
+
+		case key == "esc":
+			return s, func() tea.Msg { return stagingCloseMsg{} }
+
+		case key == "/":
+			// Narrow the list to matching paths
+			s.filtering = true
+			s.filterInput.SetValue(s.filter)
+			s.filterInput.CursorEnd()
+			return s, s.filterInput.Focus()
+
+		case s.keys.Down.Matches(key):
 			if s.cursor < len(s.files)-1 {
 				s.cursor++
+				s.hunks.cursor = 0
 				if s.showDiff {
 					return s, s.loadDiff()
 				}
 			}
 
-		case "k", "up":
+		case s.keys.Up.Matches(key):
 			if s.cursor > 0 {
 				s.cursor--
+				s.hunks.cursor = 0
 				if s.showDiff {
 					return s, s.loadDiff()
 				}
 			}
 
-		case "d":
+		case key == "enter":
+			// Expand a collapsed directory, or collapse the one the file is in
+			return s, s.toggleExpand()
+
+		case key == "i":
+			// Toggle listing ignored files
+			s.showIgnored = !s.showIgnored
+			return s, s.loadFiles()
+
+		case key == "d":
 			// Toggle diff preview
 			s.showDiff = !s.showDiff
+			s.hunks.cursor = 0
 			if s.showDiff {
 				return s, s.loadDiff()
 			}
 
-		case " ":
+		case key == "tab":
+			// Move focus into the diff to work with hunks
+			if s.showDiff && len(s.hunks.items) > 0 {
+				s.diffFocus = true
+			}
+
+		case key == " ":
 			// Stage/unstage file
 			return s, s.toggleStage()
 
-		case "a":
-			// Stage all
+		case key == "a" || key == "A":
+			// Stage all, or all the filter matches
 			return s, s.stageAll()
 
-		case "r":
+		case key == "C":
+			// Pick a commit to compare the staged file against
+			return s, s.openPicker()
+
+		case key == "x":
+			// Discard the selected file's working-tree changes
+			return s, s.confirmDiscard()
+
+		case key == "X":
+			// Discard all working-tree changes
+			return s, s.confirmDiscardAll()
+
+		case key == "h":
+			// Browse the commits that touched the selected file
+			return s, s.openHistory()
+
+		case key == "e":
+			// Open the selected file in the editor, e.g. to resolve conflicts
+			return s, s.openInEditor()
+
+		case key == "r":
 			// Refresh
 			return s, s.loadFiles()
+
+		case key == "v":
+			// Toggle side-by-side diff
+			s.splitDiff = !s.splitDiff
+
+		case key == "w":
+			// Toggle word-level highlighting
+			s.wordDiff = !s.wordDiff
+
+		case key == ".":
+			// Toggle files hidden by exclude_paths
+			s.showHidden = !s.showHidden
+			s.applyExcludes()
+			if s.showDiff {
+				return s, s.loadDiff()
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -120,13 +284,268 @@ func (s *StagingView) Update(msg tea.Msg) (*StagingView, tea.Cmd) {
 	return s, nil
 }
 
+// openInEditor suspends the TUI and opens the selected file in the editor
+func (s *StagingView) openInEditor() tea.Cmd {
+	if s.cursor < 0 || s.cursor >= len(s.files) {
+		return nil
+	}
+
+	path := filepath.Join(git.Current().Root, s.files[s.cursor].Path)
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return editorClosedMsg{err}
+	})
+}
+
+// openPicker opens the commit graph to choose what to compare against
+func (s *StagingView) openPicker() tea.Cmd {
+	if s.cursor < 0 || s.cursor >= len(s.files) || s.files[s.cursor].IsUntracked {
+		return nil
+	}
+
+	s.picker = NewGraphView(s.cfg, s.theme)
+	s.picker.picking = true
+	s.picker, _ = s.picker.Update(tea.WindowSizeMsg{Width: s.width, Height: s.height})
+	return s.picker.Init()
+}
+
+// updatePicker forwards the picker's messages to it and closes it once a
+// commit is picked. Anything else is left for the staging view.
+func (s *StagingView) updatePicker(msg tea.Msg) (tea.Cmd, bool) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case commitPickedMsg:
+		s.picker = nil
+		if msg.hash == "" {
+			return nil, true
+		}
+		s.compareTo = msg.hash
+		s.showDiff = true
+		s.diffFocus = false
+		s.hunks.cursor = 0
+		return s.loadDiff(), true
+
+	case tea.WindowSizeMsg:
+		s.picker, _ = s.picker.Update(msg)
+		return nil, false
+
+	case tea.KeyMsg, commitsLoadedMsg, errMsg:
+		s.picker, cmd = s.picker.Update(msg)
+		return cmd, true
+	}
+	return nil, false
+}
+
+// openHistory opens the commit graph narrowed to the selected file
+func (s *StagingView) openHistory() tea.Cmd {
+	if s.cursor < 0 || s.cursor >= len(s.files) || s.files[s.cursor].IsUntracked {
+		return nil
+	}
+
+	s.history = NewGraphView(s.cfg, s.theme)
+	s.history.path = s.files[s.cursor].Path
+	s.history, _ = s.history.Update(tea.WindowSizeMsg{Width: s.width, Height: s.height})
+	return s.history.Init()
+}
+
+// updateHistory forwards messages to the open file history until it is
+// closed. The staging view's own messages are left for it.
+func (s *StagingView) updateHistory(msg tea.Msg) (tea.Cmd, bool) {
+	var cmd tea.Cmd
+	switch msg.(type) {
+	case graphCloseMsg:
+		s.history = nil
+		return nil, true
+
+	case filesLoadedMsg, diffLoadedMsg, editorClosedMsg:
+		return nil, false
+
+	case tea.WindowSizeMsg:
+		s.history, _ = s.history.Update(msg)
+		return nil, false
+	}
+
+	s.history, cmd = s.history.Update(msg)
+	return cmd, true
+}
+
+// updateDiffFocus handles keys while the diff pane has focus
+func (s *StagingView) updateDiffFocus(msg tea.KeyMsg) tea.Cmd {
+	switch key := msg.String(); {
+	case key == "tab" || key == "esc":
+		s.diffFocus = false
+
+	case s.keys.Down.Matches(key):
+		s.hunks.down()
+
+	case s.keys.Up.Matches(key):
+		s.hunks.up()
+
+	case key == " ":
+		// Stage/unstage the selected hunk
+		return s.toggleHunk()
+
+	case key == "v":
+		s.splitDiff = !s.splitDiff
+
+	case key == "w":
+		s.wordDiff = !s.wordDiff
+	}
+
+	return nil
+}
+
+func (s *StagingView) toggleHunk() tea.Cmd {
+	if s.cursor < 0 || s.cursor >= len(s.files) {
+		return nil
+	}
+
+	// Reload files after staging, which also reloads the diff
+	opts := s.statusOptions()
+	return s.hunks.toggle(s.files[s.cursor].Path, func() tea.Msg {
+		files, err := git.GetWorkingTreeStatusWith(opts)
+		if err != nil {
+			return errMsg{err}
+		}
+		return filesLoadedMsg{files}
+	})
+}
+
+// FocusFile selects path and shows its diff once the file list has loaded
+func (s *StagingView) FocusFile(path string) {
+	s.focusPath = path
+	s.showDiff = true
+}
+
+// selectPath moves the cursor to path, revealing it if exclude_paths hides it
+func (s *StagingView) selectPath(path string) {
+	if s.cfg.IsExcluded(path) && !s.showHidden {
+		s.showHidden = true
+		s.applyExcludes()
+	}
+	if i := indexOfPath(s.files, path); i >= 0 {
+		s.cursor = i
+	}
+}
+
+// statusOptions returns the options the file list is loaded with
+func (s *StagingView) statusOptions() git.StatusOptions {
+	opts := git.StatusOptions{Ignored: s.showIgnored}
+	for dir := range s.expanded {
+		opts.Expand = append(opts.Expand, dir)
+	}
+	return opts
+}
+
+// toggleExpand lists a collapsed untracked or ignored directory file by
+// file, or collapses the expanded directory the selected file is in
+func (s *StagingView) toggleExpand() tea.Cmd {
+	if s.cursor < 0 || s.cursor >= len(s.files) {
+		return nil
+	}
+
+	file := s.files[s.cursor]
+	if file.IsDir() {
+		s.expanded[file.Path] = true
+		return s.loadFiles()
+	}
+	if dir := s.expandedDir(file); dir != "" {
+		delete(s.expanded, dir)
+		s.focusPath = dir
+		return s.loadFiles()
+	}
+	return nil
+}
+
+// expandedDir returns the expanded directory file was listed from, or ""
+func (s *StagingView) expandedDir(file models.FileChange) string {
+	if !file.IsUntracked && !file.IsIgnored {
+		return ""
+	}
+	for dir := range s.expanded {
+		if strings.HasPrefix(file.Path, dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// selectedPath returns the path under the cursor, empty when there is none
+func (s *StagingView) selectedPath() string {
+	if s.cursor < 0 || s.cursor >= len(s.files) {
+		return ""
+	}
+	return s.files[s.cursor].Path
+}
+
+// indexOfPath finds the file with path, matching the old path of renames,
+// and returns -1 if it isn't in the list
+func indexOfPath(files []models.FileChange, path string) int {
+	if path == "" {
+		return -1
+	}
+	for i, file := range files {
+		if file.Path == path || file.OldPath == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// groupedFiles orders files section by section, the way the grouped file
+// lists show them
+func groupedFiles(files []models.FileChange) []models.FileChange {
+	grouped := make([]models.FileChange, 0, len(files))
+	for _, group := range models.GroupFiles(files) {
+		grouped = append(grouped, group.Files...)
+	}
+	return grouped
+}
+
+// applyExcludes filters the file list against the configured exclude
+// patterns and the filter, and orders it by section
+func (s *StagingView) applyExcludes() {
+	s.files = s.allFiles
+	s.hiddenCount = 0
+
+	if (!s.showHidden && len(s.cfg.ExcludePaths) > 0) || s.filter != "" {
+		s.files = []models.FileChange{}
+		for _, file := range s.allFiles {
+			if !s.showHidden && s.cfg.IsExcluded(file.Path) {
+				s.hiddenCount++
+				continue
+			}
+			if !matchesFilter(file, s.filter) {
+				continue
+			}
+			s.files = append(s.files, file)
+		}
+	}
+	s.files = groupedFiles(s.files)
+
+	if s.cursor >= len(s.files) {
+		s.cursor = len(s.files) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+}
+
 func (s *StagingView) toggleStage() tea.Cmd {
 	if s.cursor < 0 || s.cursor >= len(s.files) {
 		return nil
 	}
 
 	file := s.files[s.cursor]
+	if file.IsConflicted {
+		return s.markResolved(file.Path)
+	}
+	if file.IsIgnored {
+		return func() tea.Msg {
+			return errMsg{fmt.Errorf("%s is ignored, remove it from .gitignore to stage it", file.Path)}
+		}
+	}
 
+	opts := s.statusOptions()
 	return func() tea.Msg {
 		var err error
 		if file.IsStaged {
@@ -140,23 +559,121 @@ func (s *StagingView) toggleStage() tea.Cmd {
 		}
 
 		// Reload files after staging
-		files, err := git.GetWorkingTreeStatus()
+		files, err := git.GetWorkingTreeStatusWith(opts)
+		if err != nil {
+			return errMsg{err}
+		}
+		return filesLoadedMsg{files}
+	}
+}
+
+// markResolved stages a conflicted file, asking first if it still has
+// conflict markers in it
+func (s *StagingView) markResolved(path string) tea.Cmd {
+	opts := s.statusOptions()
+	resolve := func() tea.Msg {
+		if err := git.MarkResolved(path); err != nil {
+			return errMsg{err}
+		}
+		files, err := git.GetWorkingTreeStatusWith(opts)
 		if err != nil {
 			return errMsg{err}
 		}
 		return filesLoadedMsg{files}
 	}
+
+	if git.HasConflictMarkers(path) {
+		return requestConfirm(fmt.Sprintf("%s still contains conflict markers. Mark it resolved anyway?", path), resolve, nil)
+	}
+	return resolve
+}
+
+// typing reports whether keys go to a text prompt, here or in the commit
+// picker or file history on top
+func (s *StagingView) typing() bool {
+	return s.filtering || (s.picker != nil && s.picker.typing()) || (s.history != nil && s.history.typing())
 }
 
+// updateFilterInput handles keys while the filter prompt is open
+func (s *StagingView) updateFilterInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		s.filtering = false
+		s.filterInput.Blur()
+		return s.setFilter(strings.TrimSpace(s.filterInput.Value()))
+	case "esc":
+		s.filtering = false
+		s.filterInput.Blur()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	s.filterInput, cmd = s.filterInput.Update(msg)
+	return cmd
+}
+
+// setFilter narrows the list to the files matching filter, keeping the
+// selected file selected when it still matches
+func (s *StagingView) setFilter(filter string) tea.Cmd {
+	selected := s.selectedPath()
+	s.filter = filter
+	s.applyExcludes()
+	if i := indexOfPath(s.files, selected); i >= 0 {
+		s.cursor = i
+	}
+	if s.selectedPath() != selected {
+		s.hunks.cursor = 0
+		if s.showDiff {
+			return s.loadDiff()
+		}
+	}
+	return nil
+}
+
+// matchesFilter reports whether a file is listed under filter. A filter
+// with glob characters matches the whole path or just the file name, so
+// *.go finds Go files in every directory. Any other filter matches part of
+// the path, ignoring case.
+func matchesFilter(file models.FileChange, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	path := strings.TrimSuffix(file.Path, "/")
+	if strings.ContainsAny(filter, "*?[") {
+		if ok, _ := filepath.Match(filter, path); ok {
+			return true
+		}
+		ok, _ := filepath.Match(filter, filepath.Base(path))
+		return ok
+	}
+	return strings.Contains(strings.ToLower(path), strings.ToLower(filter))
+}
+
+// stageAll stages the changes to every listed file, so files hidden by
+// exclude_paths or the filter are left alone
 func (s *StagingView) stageAll() tea.Cmd {
+	var paths []string
+	for _, file := range s.files {
+		// Conflicts are resolved one by one, through markResolved's check
+		// for leftover markers, and ignored files stay out
+		if file.IsConflicted || file.IsIgnored {
+			continue
+		}
+		paths = append(paths, file.Path)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	opts := s.statusOptions()
 	return func() tea.Msg {
-		err := git.StageAll()
+		err := git.StageFiles(paths)
 		if err != nil {
 			return errMsg{err}
 		}
 
 		// Reload files
-		files, err := git.GetWorkingTreeStatus()
+		files, err := git.GetWorkingTreeStatusWith(opts)
 		if err != nil {
 			return errMsg{err}
 		}
@@ -164,10 +681,78 @@ func (s *StagingView) stageAll() tea.Cmd {
 	}
 }
 
+// confirmDiscard asks before discarding the selected file's working-tree
+// changes, warning that untracked files are deleted for good
+func (s *StagingView) confirmDiscard() tea.Cmd {
+	if s.cursor < 0 || s.cursor >= len(s.files) {
+		return nil
+	}
+
+	file := s.files[s.cursor]
+	if file.IsIgnored || (!file.IsUntracked && file.Status == "") {
+		// Ignored, or only staged changes, nothing in the working tree to discard
+		return nil
+	}
+
+	prompt := fmt.Sprintf("Discard the working-tree changes to %s?\n\nStaged changes are kept.", file.Path)
+	if file.IsUntracked {
+		prompt = fmt.Sprintf("Delete untracked %s from disk?\n\nThis cannot be undone.", file.Path)
+	}
+
+	opts := s.statusOptions()
+	return requestConfirm(prompt, func() tea.Msg {
+		if err := git.DiscardFile(file.Path, file.IsUntracked); err != nil {
+			return errMsg{err}
+		}
+
+		files, err := git.GetWorkingTreeStatusWith(opts)
+		if err != nil {
+			return errMsg{err}
+		}
+		return filesLoadedMsg{files}
+	}, nil)
+}
+
+// confirmDiscardAll asks before discarding every working-tree change,
+// spelling out how many untracked files would be deleted
+func (s *StagingView) confirmDiscardAll() tea.Cmd {
+	untracked := 0
+	for _, f := range s.allFiles {
+		if f.IsUntracked {
+			untracked++
+		}
+	}
+
+	prompt := "Discard all working-tree changes?\n\nStaged changes are kept."
+	if untracked > 0 {
+		prompt = fmt.Sprintf("Discard all working-tree changes and delete %d untracked file(s) from disk?\n\nStaged changes are kept. Deleted files cannot be recovered.", untracked)
+	}
+
+	opts := s.statusOptions()
+	return requestConfirm(prompt, func() tea.Msg {
+		if err := git.DiscardAll(); err != nil {
+			return errMsg{err}
+		}
+
+		files, err := git.GetWorkingTreeStatusWith(opts)
+		if err != nil {
+			return errMsg{err}
+		}
+		return filesLoadedMsg{files}
+	}, nil)
+}
+
 func (s *StagingView) View() string {
-	if len(s.files) == 0 {
+	if s.picker != nil {
+		return s.picker.View()
+	}
+	if s.history != nil {
+		return s.history.View()
+	}
+
+	if len(s.files) == 0 && s.filter == "" && !s.filtering {
 		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
+			Foreground(s.theme.Muted).
 			Render("No changes to display\n\nPress 'b' to view branches")
 	}
 
@@ -177,7 +762,7 @@ func (s *StagingView) View() string {
 	b.WriteString(s.renderFileList())
 
 	// Diff preview (if enabled)
-	if s.showDiff {
+	if s.showDiff && len(s.files) > 0 {
 		b.WriteString("\n\n")
 		b.WriteString(s.renderDiff())
 	}
@@ -186,21 +771,16 @@ func (s *StagingView) View() string {
 }
 
 func (s *StagingView) renderFileList() string {
-	statusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("yellow")).
-		Width(3)
+	conflictStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Danger).
+		Bold(true)
 
-	pathStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("white"))
-
-	stagedPathStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("green"))
-
-	selectedStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("238"))
+	groupHeaderStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Secondary).
+		Bold(true)
 
 	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("cyan")).
+		Foreground(s.theme.Accent).
 		Bold(true).
 		MarginBottom(1)
 
@@ -214,81 +794,217 @@ func (s *StagingView) renderFileList() string {
 		}
 	}
 	header := fmt.Sprintf("Changes (%d total, %d staged)", len(s.files), stagedCount)
-	b.WriteString(headerStyle.Render(header) + "\n")
+	if s.hiddenCount > 0 {
+		header += fmt.Sprintf(" • %d hidden", s.hiddenCount)
+	}
+	if s.showIgnored {
+		header += " • ignored shown"
+	}
+	if s.filter != "" {
+		header += fmt.Sprintf(" • matching %s (a: stage all matching, esc: clear)", s.filter)
+	}
+	header = headerStyle.Render(header)
+	if conflicted := s.conflictCount(); conflicted > 0 {
+		header += conflictStyle.Render(fmt.Sprintf(" • %d conflicted (e: open in editor, space: mark resolved)", conflicted))
+	}
+	b.WriteString(header + "\n")
+
+	if s.filtering {
+		b.WriteString(s.filterInput.View() + "\n")
+	}
+	if len(s.files) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(s.theme.Muted).Render("  No changed files match") + "\n")
+	}
+
+	// Files, under a header per section. s.files is already in section
+	// order, so i follows the cursor while headers are only rendered.
+	var rows []string
+	cursorRow := 0
+	i := 0
+	for _, group := range models.GroupFiles(s.files) {
+		rows = append(rows, groupHeaderStyle.Render(fmt.Sprintf("%s (%d)", group.Title, len(group.Files))))
+		for _, file := range group.Files {
+			if i == s.cursor {
+				cursorRow = len(rows)
+			}
+			rows = append(rows, s.renderFileRow(file, i == s.cursor))
+			i++
+		}
+	}
 
-	// Files
 	visibleHeight := s.height - 10 // Leave room for header/footer/diff
 	if visibleHeight < 5 {
 		visibleHeight = 5
 	}
 
-	start := s.cursor - visibleHeight/2
-	if start < 0 {
-		start = 0
+	first := cursorRow - visibleHeight/2
+	if first < 0 {
+		first = 0
 	}
-	end := start + visibleHeight
-	if end > len(s.files) {
-		end = len(s.files)
-		start = end - visibleHeight
-		if start < 0 {
-			start = 0
+	last := first + visibleHeight
+	if last > len(rows) {
+		last = len(rows)
+		first = last - visibleHeight
+		if first < 0 {
+			first = 0
 		}
 	}
 
-	for i := start; i < end; i++ {
-		file := s.files[i]
+	for _, row := range rows[first:last] {
+		b.WriteString(row + "\n")
+	}
 
-		status := statusStyle.Render(file.DisplayStatus())
+	return b.String()
+}
 
-		var path string
-		if file.IsStaged {
-			path = stagedPathStyle.Render(file.Path)
-		} else {
-			path = pathStyle.Render(file.Path)
-		}
+// renderFileRow renders one file of the list, with a hint for expanding or
+// collapsing directories when it is selected
+func (s *StagingView) renderFileRow(file models.FileChange, selected bool) string {
+	statusStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Highlight).
+		Width(3)
 
-		line := fmt.Sprintf("%s %s", status, path)
+	pathStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Text)
 
-		if i == s.cursor {
-			line = selectedStyle.Render("▸ " + line)
-		} else {
-			line = "  " + line
-		}
+	stagedPathStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Branch)
 
-		b.WriteString(line + "\n")
+	conflictStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Danger).
+		Bold(true)
+
+	ignoredStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Subtle)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Muted)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(s.theme.Selection)
+
+	status := statusStyle.Render(file.DisplayStatus())
+	if file.IsConflicted {
+		status = conflictStyle.Width(3).Render(file.DisplayStatus())
 	}
 
-	return b.String()
+	displayPath := file.Path
+	if file.OldPath != "" {
+		displayPath = file.OldPath + " → " + file.Path
+	}
+
+	var path string
+	if file.IsConflicted {
+		path = conflictStyle.Render(displayPath)
+	} else if file.IsIgnored {
+		path = ignoredStyle.Render(displayPath)
+	} else if file.IsStaged {
+		path = stagedPathStyle.Render(displayPath)
+	} else {
+		path = pathStyle.Render(displayPath)
+	}
+
+	line := fmt.Sprintf("%s %s", status, path)
+
+	if !selected {
+		return "  " + line
+	}
+	if file.IsDir() {
+		line += hintStyle.Render(" (enter: expand)")
+	} else if dir := s.expandedDir(file); dir != "" {
+		line += hintStyle.Render(" (enter: collapse " + dir + ")")
+	}
+	return selectedStyle.Render("▸ " + line)
+}
+
+// conflictCount counts the listed files with unresolved conflicts
+func (s *StagingView) conflictCount() int {
+	count := 0
+	for _, file := range s.files {
+		if file.IsConflicted {
+			count++
+		}
+	}
+	return count
 }
 
 func (s *StagingView) renderDiff() string {
 	dividerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("238"))
+		Foreground(s.theme.Border)
 
 	diffStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("white")).
 		MaxHeight(s.height / 2)
 
 	divider := dividerStyle.Render(strings.Repeat("─", s.width))
+	if s.compareTo != "" {
+		labelStyle := lipgloss.NewStyle().Foreground(s.theme.Accent)
+		hashStyle := lipgloss.NewStyle().Foreground(s.theme.Highlight)
+		helpStyle := lipgloss.NewStyle().Foreground(s.theme.Muted)
+		hash := s.compareTo
+		if !s.cfg.FullHashes && len(hash) > 7 {
+			hash = hash[:7]
+		}
+		divider += "\n" + labelStyle.Render("Staged vs ") + hashStyle.Render(hash) +
+			helpStyle.Render(" • C: pick another commit • esc: back to the usual diff")
+	}
 
-	if s.diff == "" {
+	if s.diff == "" && s.unstaged == "" {
 		return divider + "\n" + lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
+			Foreground(s.theme.Muted).
 			Render("No diff available")
 	}
 
-	// Limit diff lines
-	lines := strings.Split(s.diff, "\n")
 	maxLines := s.height/2 - 3
 	if maxLines < 5 {
 		maxLines = 5
 	}
-	if len(lines) > maxLines {
-		lines = lines[:maxLines]
-		lines = append(lines, "... (truncated)")
+
+	if s.diffFocus {
+		return divider + "\n" + s.hunks.render(s.theme, s.width, maxLines, s.splitDiff, s.wordDiff, "tab: back to files")
 	}
 
-	return divider + "\n" + diffStyle.Render(strings.Join(lines, "\n"))
+	if s.unstaged != "" {
+		// Split the room between both sides, each under its own header
+		half := maxLines/2 - 1
+		return divider + "\n" +
+			s.renderDiffSection("Staged", s.diff, half) + "\n" +
+			s.renderDiffSection("Unstaged", s.unstaged, maxLines-half-2)
+	}
+
+	// Side-by-side needs room for two columns, fall back to unified otherwise
+	if s.splitDiff && s.width >= minSplitDiffWidth {
+		return divider + "\n" + renderSplitDiff(s.theme, s.diff, s.width, maxLines)
+	}
+
+	return divider + "\n" + diffStyle.Render(s.colorizeDiff(s.diff, maxLines))
+}
+
+// renderDiffSection renders one side of a partially staged file's diff
+// under a header, in at most maxLines lines below it
+func (s *StagingView) renderDiffSection(title, diff string, maxLines int) string {
+	header := diffSectionHeader(s.theme, title)
+	if diff == "" {
+		return header + "\n" + lipgloss.NewStyle().Foreground(s.theme.Muted).Render("No changes")
+	}
+	if s.splitDiff && s.width >= minSplitDiffWidth {
+		return header + "\n" + renderSplitDiff(s.theme, diff, s.width, maxLines)
+	}
+	return header + "\n" + s.colorizeDiff(diff, maxLines)
+}
+
+// colorizeDiff colors the first maxLines lines of diff, noting when lines
+// were cut. Only the visible part is colored since this runs every render.
+func (s *StagingView) colorizeDiff(diff string, maxLines int) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	truncated := len(lines) > maxLines
+	if truncated {
+		lines = lines[:maxLines]
+	}
+	text := colorizeDiff(s.theme, strings.Join(lines, "\n"), s.wordDiff)
+	if truncated {
+		text += "\n... (truncated)"
+	}
+	return text
 }
 
 func (s *StagingView) HasStagedFiles() bool {
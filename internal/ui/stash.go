@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+	"github.com/Johannes-Berggren/GitGoblin/internal/git"
+	"github.com/Johannes-Berggren/GitGoblin/internal/models"
+)
+
+type stashesLoadedMsg struct {
+	stashes []models.Stash
+}
+
+// stashPoppedMsg reports the result of popping or applying a stash
+type stashPoppedMsg struct {
+	index   int
+	applied bool // applied without dropping it from the list
+	err     error
+}
+
+// stashDroppedMsg reports the result of dropping a stash
+type stashDroppedMsg struct {
+	index int
+	err   error
+}
+
+// stashPreviewMsg carries the patch of the stash at index
+type stashPreviewMsg struct {
+	index int
+	patch string
+	err   error
+}
+
+type stashCloseMsg struct{}
+
+type StashView struct {
+	theme   *Theme
+	stashes []models.Stash
+	loaded  bool
+	loader  loader
+	cursor  int
+	keys    config.KeyMap
+	preview viewport.Model // patch of the selected stash
+	status  string
+	err     error
+	width   int
+	height  int
+}
+
+func NewStashView(cfg *config.Config, theme *Theme) *StashView {
+	// j/k move through the list, so the preview only scrolls by page
+	vp := viewport.New(0, 0)
+	vp.KeyMap = viewport.KeyMap{
+		PageDown:     key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:       key.NewBinding(key.WithKeys("pgup")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+	}
+
+	return &StashView{
+		theme:   theme,
+		loader:  newLoader(theme),
+		cursor:  0,
+		keys:    cfg.Keys,
+		preview: vp,
+	}
+}
+
+func (s *StashView) Init() tea.Cmd {
+	return s.loadStashes()
+}
+
+func (s *StashView) loadStashes() tea.Cmd {
+	return s.loader.start(func() tea.Msg {
+		stashes, err := git.StashList()
+		if err != nil {
+			return errMsg{err}
+		}
+		return stashesLoadedMsg{stashes}
+	})
+}
+
+// loadPreview fetches the patch of the selected stash
+func (s *StashView) loadPreview() tea.Cmd {
+	stash := s.SelectedStash()
+	if stash == nil {
+		s.preview.SetContent("")
+		return nil
+	}
+
+	index := stash.Index
+	return func() tea.Msg {
+		patch, err := git.StashShow(index)
+		return stashPreviewMsg{index: index, patch: patch, err: err}
+	}
+}
+
+func (s *StashView) Update(msg tea.Msg) (*StashView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case stashesLoadedMsg:
+		s.stashes = msg.stashes
+		s.loaded = true
+		s.loader.stop()
+		if s.cursor >= len(s.stashes) {
+			s.cursor = len(s.stashes) - 1
+		}
+		if s.cursor < 0 {
+			s.cursor = 0
+		}
+		s.resizePreview()
+		return s, s.loadPreview()
+
+	case stashPreviewMsg:
+		// Skip previews for a stash that is no longer selected
+		if stash := s.SelectedStash(); stash == nil || stash.Index != msg.index {
+			return s, nil
+		}
+		if msg.err != nil {
+			s.preview.SetContent(lipgloss.NewStyle().
+				Foreground(s.theme.StatusError).
+				Render(fmt.Sprintf("Error: %v", msg.err)))
+		} else {
+			s.preview.SetContent(colorizeDiff(s.theme, expandTabs(msg.patch), true))
+		}
+		s.preview.GotoTop()
+		return s, nil
+
+	case stashDroppedMsg:
+		if msg.err != nil {
+			s.err = msg.err
+			return s, nil
+		}
+		s.err = nil
+		s.status = fmt.Sprintf("Dropped stash@{%d}", msg.index)
+		return s, s.loadStashes()
+
+	case errMsg:
+		s.err = msg.err
+		s.loaded = true
+		s.loader.stop()
+
+	case spinner.TickMsg:
+		return s, s.loader.tick(msg)
+
+	case tea.KeyMsg:
+		switch key := msg.String(); {
+		case key == "esc":
+			return s, func() tea.Msg { return stashCloseMsg{} }
+
+		case s.keys.Down.Matches(key):
+			if s.cursor < len(s.stashes)-1 {
+				s.cursor++
+				return s, s.loadPreview()
+			}
+
+		case s.keys.Up.Matches(key):
+			if s.cursor > 0 {
+				s.cursor--
+				return s, s.loadPreview()
+			}
+
+		case key == "enter":
+			if stash := s.SelectedStash(); stash != nil {
+				index := stash.Index
+				return s, func() tea.Msg {
+					return stashPoppedMsg{index: index, err: git.StashPop(index)}
+				}
+			}
+
+		case key == "a":
+			// Apply, keeping the stash around
+			if stash := s.SelectedStash(); stash != nil {
+				index := stash.Index
+				return s, func() tea.Msg {
+					return stashPoppedMsg{index: index, applied: true, err: git.StashApply(index)}
+				}
+			}
+
+		case key == "d":
+			if stash := s.SelectedStash(); stash != nil {
+				index := stash.Index
+				prompt := fmt.Sprintf("Drop stash@{%d} (%s)?\n\nIts changes will be lost.", index, stash.Message)
+				return s, requestConfirm(prompt, func() tea.Msg {
+					return stashDroppedMsg{index: index, err: git.StashDrop(index)}
+				}, nil)
+			}
+
+		case key == "r":
+			return s, s.loadStashes()
+
+		default:
+			// Page keys scroll the preview
+			var cmd tea.Cmd
+			s.preview, cmd = s.preview.Update(msg)
+			return s, cmd
+		}
+
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+		s.resizePreview()
+	}
+
+	return s, nil
+}
+
+// resizePreview gives the preview whatever height the list leaves free
+func (s *StashView) resizePreview() {
+	listHeight := len(s.stashes) + 6 // header, divider, status and help lines
+	if len(s.stashes) == 0 {
+		listHeight++
+	}
+
+	s.preview.Width = s.width
+	s.preview.Height = s.height - listHeight
+	if s.preview.Height < 5 {
+		s.preview.Height = 5
+	}
+}
+
+func (s *StashView) View() string {
+	grayStyle := lipgloss.NewStyle().Foreground(s.theme.Muted)
+
+	if !s.loaded {
+		return s.loader.view("Loading stashes...")
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(s.theme.Accent).
+		Bold(true).
+		MarginBottom(1)
+
+	refStyle := lipgloss.NewStyle().Foreground(s.theme.Highlight)
+	branchStyle := lipgloss.NewStyle().Foreground(s.theme.Branch)
+	messageStyle := lipgloss.NewStyle().Foreground(s.theme.Text)
+	dateStyle := lipgloss.NewStyle().Foreground(s.theme.Secondary)
+	selectedStyle := lipgloss.NewStyle().Background(s.theme.Selection)
+	dividerStyle := lipgloss.NewStyle().Foreground(s.theme.Border)
+	statusStyle := lipgloss.NewStyle().Foreground(s.theme.StatusOK)
+	errorStyle := lipgloss.NewStyle().Foreground(s.theme.StatusError)
+
+	var out strings.Builder
+
+	out.WriteString(headerStyle.Render(fmt.Sprintf("Stashes (%d)", len(s.stashes))+s.loader.indicator()) + "\n")
+
+	if len(s.stashes) == 0 {
+		out.WriteString(grayStyle.Render("  No stashes") + "\n")
+	}
+
+	for i, stash := range s.stashes {
+		line := refStyle.Render(fmt.Sprintf("stash@{%d}", stash.Index))
+		if stash.Branch != "" {
+			line += " " + branchStyle.Render(stash.Branch)
+		}
+		line += " " + messageStyle.Render(stash.Message)
+		line += " " + dateStyle.Render(formatRelativeTime(stash.Date))
+
+		if i == s.cursor {
+			line = selectedStyle.Render("▸ " + line)
+		} else {
+			line = "  " + line
+		}
+
+		out.WriteString(line + "\n")
+	}
+
+	if s.err != nil {
+		out.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %v", s.err)) + "\n")
+	} else if s.status != "" {
+		out.WriteString("\n" + statusStyle.Render(s.status) + "\n")
+	}
+
+	if len(s.stashes) > 0 {
+		out.WriteString(dividerStyle.Render(strings.Repeat("─", s.width)) + "\n")
+		out.WriteString(s.preview.View() + "\n")
+	}
+
+	out.WriteString("\n" + grayStyle.Render("enter: pop • a: apply • d: drop • pgup/pgdn: scroll preview • r: refresh • esc: back"))
+
+	return out.String()
+}
+
+func (s *StashView) SelectedStash() *models.Stash {
+	if s.cursor >= 0 && s.cursor < len(s.stashes) {
+		return &s.stashes[s.cursor]
+	}
+	return nil
+}
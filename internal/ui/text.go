@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// truncateMiddle shortens s to at most maxWidth display cells by replacing
+// its middle with "…", keeping both the prefix (e.g. "feature/") and the
+// distinguishing tail of long names
+func truncateMiddle(s string, maxWidth int) string {
+	if lipgloss.Width(s) <= maxWidth {
+		return s
+	}
+	if maxWidth <= 1 {
+		return "…"
+	}
+
+	runes := []rune(s)
+	budget := maxWidth - 1
+	headBudget := (budget + 1) / 2
+	tailBudget := budget - headBudget
+
+	head := []rune{}
+	width := 0
+	for _, r := range runes {
+		w := lipgloss.Width(string(r))
+		if width+w > headBudget {
+			break
+		}
+		head = append(head, r)
+		width += w
+	}
+
+	tail := []rune{}
+	width = 0
+	for i := len(runes) - 1; i >= len(head); i-- {
+		w := lipgloss.Width(string(runes[i]))
+		if width+w > tailBudget {
+			break
+		}
+		tail = append([]rune{runes[i]}, tail...)
+		width += w
+	}
+
+	return string(head) + "…" + string(tail)
+}
+
+// truncateLeft shortens s to at most maxWidth display cells by replacing
+// its start with "...", keeping the end of paths where the filename is.
+// It counts display cells rather than bytes, so multibyte and wide
+// characters are never split.
+func truncateLeft(s string, maxWidth int) string {
+	if lipgloss.Width(s) <= maxWidth {
+		return s
+	}
+	if maxWidth <= 3 {
+		return "..."
+	}
+
+	runes := []rune(s)
+	budget := maxWidth - 3
+	start := len(runes)
+	width := 0
+	for start > 0 {
+		w := lipgloss.Width(string(runes[start-1]))
+		if width+w > budget {
+			break
+		}
+		start--
+		width += w
+	}
+
+	return "..." + string(runes[start:])
+}
+
+// formatRelativeTime describes how long ago t was, e.g. "3 days ago"
+func formatRelativeTime(t time.Time) string {
+	now := time.Now()
+	diff := now.Sub(t)
+
+	switch {
+	case diff < time.Minute:
+		return "just now"
+	case diff < time.Hour:
+		mins := int(diff.Minutes())
+		return fmt.Sprintf("%d min ago", mins)
+	case diff < 24*time.Hour:
+		hours := int(diff.Hours())
+		return fmt.Sprintf("%d hours ago", hours)
+	case diff < 7*24*time.Hour:
+		days := int(diff.Hours() / 24)
+		return fmt.Sprintf("%d days ago", days)
+	case diff < 30*24*time.Hour:
+		weeks := int(diff.Hours() / 24 / 7)
+		return fmt.Sprintf("%d weeks ago", weeks)
+	case diff < 365*24*time.Hour:
+		months := int(diff.Hours() / 24 / 30)
+		return fmt.Sprintf("%d months ago", months)
+	default:
+		years := int(diff.Hours() / 24 / 365)
+		return fmt.Sprintf("%d years ago", years)
+	}
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/Johannes-Berggren/GitGoblin/internal/config"
+)
+
+// Theme holds the named colors every view draws with
+type Theme struct {
+	Accent      lipgloss.Color // headers, labels and branch names
+	Text        lipgloss.Color // regular text
+	Muted       lipgloss.Color // hints and secondary information
+	Secondary   lipgloss.Color // dates, upstreams and other details
+	Subtle      lipgloss.Color // zero values and placeholders
+	Border      lipgloss.Color // box borders and separators
+	Success     lipgloss.Color // added lines, staged files, ahead counts
+	Danger      lipgloss.Color // removed lines, deleted files
+	Warning     lipgloss.Color // behind counts and warnings
+	Highlight   lipgloss.Color // commit hashes and stash refs
+	Branch      lipgloss.Color // branch names in lists and refs
+	Brand       lipgloss.Color // logo and spinners
+	Link        lipgloss.Color // prompts in text inputs
+	StatusOK    lipgloss.Color // success status messages
+	StatusError lipgloss.Color // error messages
+	Selection   lipgloss.Color // background of the selected row
+	Panel       lipgloss.Color // background of the selected row in panels
+	WarningBg   lipgloss.Color // background of warning banners
+	SuccessBg   lipgloss.Color // background of words added within a line
+	DangerBg    lipgloss.Color // background of words removed within a line
+
+	// Authors are the colors authors are told apart by in the graph, see
+	// authorColor
+	Authors []lipgloss.Color
+}
+
+// DarkTheme is the default, tuned for dark terminal backgrounds
+func DarkTheme() *Theme {
+	return &Theme{
+		Accent:      lipgloss.Color("cyan"),
+		Text:        lipgloss.Color("white"),
+		Muted:       lipgloss.Color("241"),
+		Secondary:   lipgloss.Color("244"),
+		Subtle:      lipgloss.Color("240"),
+		Border:      lipgloss.Color("240"),
+		Success:     lipgloss.Color("34"),
+		Danger:      lipgloss.Color("196"),
+		Warning:     lipgloss.Color("214"),
+		Highlight:   lipgloss.Color("yellow"),
+		Branch:      lipgloss.Color("green"),
+		Brand:       lipgloss.Color("170"),
+		Link:        lipgloss.Color("12"),
+		StatusOK:    lipgloss.Color("10"),
+		StatusError: lipgloss.Color("9"),
+		Selection:   lipgloss.Color("238"),
+		Panel:       lipgloss.Color("236"),
+		WarningBg:   lipgloss.Color("58"),
+		SuccessBg:   lipgloss.Color("22"),
+		DangerBg:    lipgloss.Color("52"),
+		Authors:     colorList("39", "208", "170", "114", "220", "75", "204", "150", "141", "180"),
+	}
+}
+
+// LightTheme trades the bright and pale colors for ones readable on a
+// light terminal background
+func LightTheme() *Theme {
+	return &Theme{
+		Accent:      lipgloss.Color("25"),
+		Text:        lipgloss.Color("235"),
+		Muted:       lipgloss.Color("243"),
+		Secondary:   lipgloss.Color("241"),
+		Subtle:      lipgloss.Color("246"),
+		Border:      lipgloss.Color("244"),
+		Success:     lipgloss.Color("28"),
+		Danger:      lipgloss.Color("160"),
+		Warning:     lipgloss.Color("166"),
+		Highlight:   lipgloss.Color("130"),
+		Branch:      lipgloss.Color("28"),
+		Brand:       lipgloss.Color("127"),
+		Link:        lipgloss.Color("26"),
+		StatusOK:    lipgloss.Color("28"),
+		StatusError: lipgloss.Color("160"),
+		Selection:   lipgloss.Color("253"),
+		Panel:       lipgloss.Color("254"),
+		WarningBg:   lipgloss.Color("230"),
+		SuccessBg:   lipgloss.Color("194"),
+		DangerBg:    lipgloss.Color("224"),
+		Authors:     colorList("25", "130", "90", "28", "94", "61", "161", "64", "54", "124"),
+	}
+}
+
+func colorList(colors ...string) []lipgloss.Color {
+	list := make([]lipgloss.Color, len(colors))
+	for i, color := range colors {
+		list[i] = lipgloss.Color(color)
+	}
+	return list
+}
+
+// authorColor picks the color of the author with email from Authors by
+// hashing the email, so an author keeps their color between refreshes and
+// runs
+func (t *Theme) authorColor(email string) lipgloss.Color {
+	if len(t.Authors) == 0 {
+		return t.Accent
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(email)))
+	return t.Authors[h.Sum32()%uint32(len(t.Authors))]
+}
+
+// LoadTheme picks the theme named in the config, "auto" choosing by the
+// terminal background reported in $COLORFGBG, and applies color overrides
+// from the [colors] table
+func LoadTheme(cfg *config.Config) (*Theme, error) {
+	var theme *Theme
+	switch cfg.Theme {
+	case "", "auto":
+		theme = DarkTheme()
+		if lightBackground(os.Getenv("COLORFGBG")) {
+			theme = LightTheme()
+		}
+	case "dark":
+		theme = DarkTheme()
+	case "light":
+		theme = LightTheme()
+	default:
+		return DarkTheme(), fmt.Errorf("unknown theme %q, expected auto, dark or light", cfg.Theme)
+	}
+
+	colors := theme.colors()
+	for name, value := range cfg.Colors {
+		color, ok := colors[name]
+		if !ok {
+			return theme, fmt.Errorf("unknown theme color %q", name)
+		}
+		*color = lipgloss.Color(value)
+	}
+
+	return theme, nil
+}
+
+// colors maps the names used in the [colors] config table to the fields
+func (t *Theme) colors() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"accent":       &t.Accent,
+		"text":         &t.Text,
+		"muted":        &t.Muted,
+		"secondary":    &t.Secondary,
+		"subtle":       &t.Subtle,
+		"border":       &t.Border,
+		"success":      &t.Success,
+		"danger":       &t.Danger,
+		"warning":      &t.Warning,
+		"highlight":    &t.Highlight,
+		"branch":       &t.Branch,
+		"brand":        &t.Brand,
+		"link":         &t.Link,
+		"status_ok":    &t.StatusOK,
+		"status_error": &t.StatusError,
+		"selection":    &t.Selection,
+		"panel":        &t.Panel,
+		"warning_bg":   &t.WarningBg,
+		"success_bg":   &t.SuccessBg,
+		"danger_bg":    &t.DangerBg,
+	}
+}
+
+// lightBackground reports whether $COLORFGBG ("fg;bg", sometimes
+// "fg;default;bg") names a light background color. Colors 7 and 9-15
+// are the light ones among the 16 basic colors.
+func lightBackground(colorfgbg string) bool {
+	parts := strings.Split(colorfgbg, ";")
+	bg, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || len(parts) < 2 {
+		return false
+	}
+	return bg == 7 || (bg >= 9 && bg <= 15)
+}